@@ -0,0 +1,179 @@
+package announce
+
+import (
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+)
+
+var spaces = regexp.MustCompile(`\s+`)
+
+// ParsePage разбирает сохраненную html страницу со списком объявлений.
+// Объявления, которые не удалось разобрать, пропускаются
+func ParsePage(r io.Reader) (model.Announces, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return model.Announces{}, err
+	}
+
+	announces, _, err := parsePage(doc)
+
+	return announces, err
+}
+
+// ParseAnnounce разбирает сохраненную html страницу с одним объявлением. Path не заполняется
+func ParseAnnounce(r io.Reader) (model.Announce, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return model.Announce{}, err
+	}
+
+	return parseDetail(doc)
+}
+
+// parsePage разбирает страницу со списком объявлений и возвращает ошибки пропущенных объявлений
+func parsePage(doc *goquery.Document) (announces model.Announces, skipped []error, err error) {
+	announces.Announces, skipped = parseAnnounces(doc)
+	announces.LastPage, err = searchLastPage(doc)
+
+	return
+}
+
+func parseAnnounces(doc *goquery.Document) ([]model.Announce, []error) {
+	announcesBlock := doc.Find("section.sf-pagewrap-area.overflow-hidden.d-flex.flex-col.justify-content-start > div > section > main > section > div > div.row").First()
+
+	announces := make([]model.Announce, 0, 10)
+	var skipped []error
+	announcesBlock.Find("div.iblock-list-item-text.p-3").Each(func(i int, s *goquery.Selection) {
+		announce, err := parseAnnounce(s)
+		if err != nil {
+			skipped = append(skipped, err)
+			return
+		}
+
+		announces = append(announces, announce)
+	})
+
+	return announces, skipped
+}
+
+func parseAnnounce(s *goquery.Selection) (announce model.Announce, err error) {
+	announce.Date, err = searchDate(s)
+	if err != nil {
+		return
+	}
+
+	announce.Path, announce.Title, err = searchAnnounceTitleAndPath(s)
+	if err != nil {
+		return
+	}
+
+	announce.Body, err = searchBody(s)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// parseDetail разбирает страницу с одним объявлением. Если блок объявления не найден,
+// заголовок, дата и текст ищутся во всем main
+func parseDetail(doc *goquery.Document) (announce model.Announce, err error) {
+	s := doc.Find("main div.iblock-detail").First()
+	if s.Length() == 0 {
+		s = doc.Find("main").First()
+	}
+
+	announce.Title = strings.TrimSpace(spaces.ReplaceAllString(s.Find("h1").First().Text(), " "))
+	if announce.Title == "" {
+		return announce, errors.New("title not found")
+	}
+
+	if announce.Date, err = searchDate(s); err != nil {
+		return
+	}
+
+	body, err := s.Find("div.iblock-detail-text").Html()
+	if err != nil {
+		return
+	}
+
+	if body == "" {
+		return announce, errors.New("body not found")
+	}
+	announce.Body = removeExtraSpaces(body)
+
+	return
+}
+
+func searchAnnounceTitleAndPath(s *goquery.Selection) (string, string, error) {
+	element := s.Find("h3 > a").First()
+
+	path, exists := element.Attr("href")
+	if !exists {
+		return "", "", errors.New("path not found")
+	}
+
+	title := strings.ReplaceAll(element.Text(), "\n", " ")
+
+	return strings.TrimSpace(path), strings.TrimSpace(title), nil
+}
+
+func searchBody(s *goquery.Selection) (string, error) {
+	body, err := s.Find("div.c-text-secondary").Html()
+	if err != nil {
+		return "", err
+	}
+
+	if body == "" {
+		return "", errors.New("body not found")
+	}
+
+	return removeExtraSpaces(body), nil
+}
+
+func searchDate(s *goquery.Selection) (string, error) {
+	date := s.Find("p.c-text-secondary").First().Text()
+	if date == "" {
+		return "", errors.New("date not found")
+	}
+
+	return strings.TrimSpace(date), nil
+}
+
+// Функция для удаления лишних пробелов между HTML-блоками
+func removeExtraSpaces(html string) string {
+	cleanedHTML := spaces.ReplaceAllString(html, " ")
+	return strings.TrimSpace(cleanedHTML)
+}
+
+func searchLastPage(doc *goquery.Document) (int, error) {
+	elements := doc.Find("main div.sf-viewbox.position-relative > div:last-child > *")
+
+	if elements.Length() == 0 {
+		return 0, errors.New("elements not found")
+	}
+
+	lastElement := elements.Last()
+
+	if lastElement.Is("span") {
+		page, err := strconv.Atoi(lastElement.Text())
+		if err != nil {
+			return 0, err
+		}
+
+		return page, nil
+	}
+
+	page, err := strconv.Atoi(lastElement.Prev().Text())
+	if err != nil {
+		return 0, err
+	}
+
+	return page, nil
+}
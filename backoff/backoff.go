@@ -0,0 +1,44 @@
+package backoff
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Delay возвращает задержку перед попыткой attempt+1: base, удвоенная после каждой неудачной попытки,
+// но не больше max. Задержка выбирается случайно от половины до полной, чтобы повторы
+// нескольких клиентов не совпадали
+func Delay(base, max time.Duration, attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := base << (attempt - 1)
+	if delay <= 0 || delay > max || attempt > 62 {
+		delay = max
+	}
+
+	if delay <= 1 {
+		return delay
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)))
+}
+
+// Sleep ждет d или отмены ctx
+func Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,28 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempt  int
+		min, max time.Duration
+	}{
+		{name: "first", attempt: 1, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		{name: "doubled", attempt: 3, min: 200 * time.Millisecond, max: 400 * time.Millisecond},
+		{name: "capped", attempt: 10, min: time.Second / 2, max: time.Second},
+		{name: "overflow", attempt: 100, min: time.Second / 2, max: time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				if got := Delay(100*time.Millisecond, time.Second, tt.attempt); got < tt.min || got > tt.max {
+					t.Fatalf("Delay() = %v, want between %v and %v", got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
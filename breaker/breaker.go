@@ -0,0 +1,135 @@
+package breaker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/chazari-x/hmtpk_parser/v2/errors"
+)
+
+// State состояние Breaker
+type State string
+
+const (
+	// Closed запросы выполняются как обычно
+	Closed State = "closed"
+	// Open сайт недоступен, запросы сразу завершаются ошибкой errors.ErrorCircuitOpen
+	Open State = "open"
+	// HalfOpen пауза после открытия прошла, один пробный запрос проверяет, доступен ли сайт
+	HalfOpen State = "half-open"
+)
+
+// Параметры Breaker по умолчанию
+const (
+	DefaultThreshold = 5
+	DefaultCooldown  = time.Second * 30
+)
+
+// Breaker размыкает цепь после нескольких неудачных запросов подряд, чтобы не ждать
+// тайм-аута каждого запроса к недоступному сайту. Через cooldown пропускается один пробный
+// запрос: если он удался, цепь замыкается, иначе снова размыкается.
+// Нулевой *Breaker пропускает все запросы
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     State
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+// New создает Breaker, который размыкается после threshold неудачных запросов подряд
+// и пропускает пробный запрос через cooldown. Если threshold не больше нуля, возвращается nil
+func New(threshold int, cooldown time.Duration) *Breaker {
+	if threshold <= 0 {
+		return nil
+	}
+
+	return &Breaker{threshold: threshold, cooldown: cooldown, state: Closed, now: time.Now}
+}
+
+// Allow проверяет, можно ли выполнить запрос. Если можно, после запроса нужно вызвать
+// Success, Failure или Cancel, иначе возвращается errors.ErrorCircuitOpen
+func (b *Breaker) Allow() error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.current() {
+	case Open:
+		return errors.ErrorCircuitOpen
+	case HalfOpen:
+		if b.probing {
+			return errors.ErrorCircuitOpen
+		}
+		b.probing = true
+	}
+
+	return nil
+}
+
+// Success отмечает удачный запрос и замыкает цепь
+func (b *Breaker) Success() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state, b.failures, b.probing = Closed, 0, false
+}
+
+// Failure отмечает неудачный запрос. Неудачный пробный запрос снова размыкает цепь
+func (b *Breaker) Failure() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.probing || b.failures >= b.threshold {
+		b.state, b.openedAt = Open, b.now()
+	}
+	b.probing = false
+}
+
+// Cancel отмечает запрос, результат которого ничего не говорит о сайте, например отмененный вызывающим
+func (b *Breaker) Cancel() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// State возвращает текущее состояние
+func (b *Breaker) State() State {
+	if b == nil {
+		return Closed
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.current()
+}
+
+// current возвращает состояние с учетом прошедшей паузы, вызывается под mu
+func (b *Breaker) current() State {
+	if b.state == Open && !b.now().Before(b.openedAt.Add(b.cooldown)) {
+		return HalfOpen
+	}
+
+	return b.state
+}
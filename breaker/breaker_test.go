@@ -0,0 +1,72 @@
+package breaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	hmtpkErrors "github.com/chazari-x/hmtpk_parser/v2/errors"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC)
+
+	b := New(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	tests := []struct {
+		name      string
+		after     time.Duration
+		result    func()
+		wantErr   error
+		wantState State
+	}{
+		{name: "first failure", result: b.Failure, wantState: Closed},
+		{name: "opened", result: b.Failure, wantState: Open},
+		{name: "fail fast", wantErr: hmtpkErrors.ErrorCircuitOpen, wantState: Open},
+		{name: "failed probe", after: time.Minute, result: b.Failure, wantState: Open},
+		{name: "canceled probe", after: time.Minute, result: b.Cancel, wantState: HalfOpen},
+		{name: "probe", result: b.Success, wantState: Closed},
+		{name: "closed", result: b.Failure, wantState: Closed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.after)
+
+			err := b.Allow()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Allow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && tt.result != nil {
+				tt.result()
+			}
+
+			if got := b.State(); got != tt.wantState {
+				t.Errorf("State() = %v, want %v", got, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestBreaker_SingleProbe(t *testing.T) {
+	now := time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC)
+
+	b := New(1, time.Minute)
+	b.now = func() time.Time { return now }
+
+	_ = b.Allow()
+	b.Failure()
+	now = now.Add(time.Minute)
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow() probe error = %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, hmtpkErrors.ErrorCircuitOpen) {
+		t.Errorf("Allow() during probe error = %v, want %v", err, hmtpkErrors.ErrorCircuitOpen)
+	}
+
+	var disabled *Breaker
+	if err := disabled.Allow(); err != nil || disabled.State() != Closed {
+		t.Errorf("nil Breaker Allow() = %v, State() = %v", err, disabled.State())
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	hmtpk "github.com/chazari-x/hmtpk_parser/v2"
+	"github.com/chazari-x/hmtpk_parser/v2/server"
+	"github.com/chazari-x/hmtpk_parser/v2/storage"
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "адрес, на котором принимаются запросы")
+	redisAddr := flag.String("redis", "", "адрес Redis для кэша, по умолчанию кэш хранится в памяти")
+	baseURL := flag.String("base-url", "", "адрес сайта колледжа, по умолчанию https://hmtpk.ru")
+	timeout := flag.Duration("timeout", time.Second*30, "тайм-аут запроса к сайту колледжа")
+	flag.Parse()
+
+	logger := logrus.New()
+
+	var cache storage.Cache = storage.NewMemory(0)
+	if *redisAddr != "" {
+		cache = storage.NewRedis(redis.NewClient(&redis.Options{Addr: *redisAddr}))
+	}
+
+	controller := hmtpk.NewController(cache, logger,
+		hmtpk.WithBaseURL(*baseURL),
+		hmtpk.WithHTTPClient(&http.Client{Timeout: *timeout}),
+	)
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           server.NewHandler(controller, logger),
+		ReadHeaderTimeout: time.Second * 10,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		defer cancel()
+
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	logger.Infof("listening on %s", *addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Fatal(err)
+	}
+}
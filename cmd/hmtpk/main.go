@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	hmtpk "github.com/chazari-x/hmtpk_parser/v2"
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+	"github.com/chazari-x/hmtpk_parser/v2/utils"
+	"github.com/sirupsen/logrus"
+)
+
+const usage = `Использование:
+  hmtpk groups [флаги]
+  hmtpk teachers [флаги]
+  hmtpk schedule group <название или идентификатор> [--date 20.03.2024] [флаги]
+  hmtpk schedule teacher <ФИО> [--date 20.03.2024] [флаги]
+  hmtpk announces [--page 1] [флаги]
+
+Флаги:
+  --format table|json|csv  формат вывода (по умолчанию table)
+  --base-url URL           адрес сайта колледжа
+  --timeout 30s            тайм-аут запроса к сайту
+`
+
+func main() {
+	if err := run(context.Background(), os.Args[1:], os.Stdout); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "hmtpk:", err)
+		if errors.Is(err, errUsage) {
+			_, _ = fmt.Fprint(os.Stderr, usage)
+		}
+		os.Exit(1)
+	}
+}
+
+var errUsage = errors.New("неверные аргументы")
+
+// command общие флаги подкоманд
+type command struct {
+	flags   *flag.FlagSet
+	format  *string
+	baseURL *string
+	timeout *time.Duration
+}
+
+func newCommand(name string) *command {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	return &command{
+		flags:   flags,
+		format:  flags.String("format", "table", "формат вывода: table, json или csv"),
+		baseURL: flags.String("base-url", "", "адрес сайта колледжа"),
+		timeout: flags.Duration("timeout", time.Second*30, "тайм-аут запроса к сайту"),
+	}
+}
+
+// parse разбирает флаги, стоящие в любом месте среди аргументов, и возвращает позиционные аргументы
+func (c *command) parse(args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := c.flags.Parse(args); err != nil {
+			return nil, fmt.Errorf("%w: %v", errUsage, err)
+		}
+
+		if c.flags.NArg() == 0 {
+			break
+		}
+
+		positional = append(positional, c.flags.Arg(0))
+		args = c.flags.Args()[1:]
+	}
+
+	switch *c.format {
+	case formatTable, formatJSON, formatCSV:
+	default:
+		return nil, fmt.Errorf("%w: неизвестный формат %q", errUsage, *c.format)
+	}
+
+	return positional, nil
+}
+
+func (c *command) controller() *hmtpk.Controller {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	return hmtpk.NewController(nil, logger,
+		hmtpk.WithBaseURL(*c.baseURL),
+		hmtpk.WithHTTPClient(&http.Client{Timeout: *c.timeout}),
+	)
+}
+
+func run(ctx context.Context, args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	cmd := newCommand(args[0])
+	switch args[0] {
+	case "groups", "teachers":
+		if _, err := cmd.parse(args[1:]); err != nil {
+			return err
+		}
+
+		controller := cmd.controller()
+		get := controller.GetGroupOptions
+		if args[0] == "teachers" {
+			get = controller.GetTeacherOptions
+		}
+
+		options, err := get(ctx)
+		if err != nil {
+			return err
+		}
+
+		return writeOptions(w, *cmd.format, options)
+	case "schedule":
+		date := cmd.flags.String("date", "", "дата в формате 02.01.2006, по умолчанию сегодня")
+		positional, err := cmd.parse(args[1:])
+		if err != nil {
+			return err
+		}
+
+		if len(positional) < 2 {
+			return errUsage
+		}
+
+		day := time.Now().In(utils.Location)
+		if *date != "" {
+			if day, err = time.ParseInLocation("02.01.2006", *date, utils.Location); err != nil {
+				return fmt.Errorf("%w: дата должна быть в формате 02.01.2006", errUsage)
+			}
+		}
+
+		weeklySchedule, err := getSchedule(ctx, cmd.controller(), positional[0], strings.Join(positional[1:], " "), day)
+		if err != nil {
+			return err
+		}
+
+		return writeSchedule(w, *cmd.format, weeklySchedule)
+	case "announces":
+		page := cmd.flags.Int("page", 1, "номер страницы")
+		if _, err := cmd.parse(args[1:]); err != nil {
+			return err
+		}
+
+		announces, err := cmd.controller().GetAnnounces(ctx, *page)
+		if err != nil {
+			return err
+		}
+
+		return writeAnnounces(w, *cmd.format, announces)
+	case "help", "-h", "--help":
+		_, err := fmt.Fprint(w, usage)
+		return err
+	default:
+		return fmt.Errorf("%w: неизвестная команда %q", errUsage, args[0])
+	}
+}
+
+func getSchedule(ctx context.Context, controller *hmtpk.Controller, kind, subject string, date time.Time) ([]model.Schedule, error) {
+	switch kind {
+	case "group":
+		weeklySchedule, err := controller.GetScheduleByGroupName(ctx, subject, utils.FormatDate(date))
+		if err != nil {
+			return nil, fmt.Errorf("группа %q: %w", subject, err)
+		}
+
+		return weeklySchedule, nil
+	case "teacher":
+		weeklySchedule, err := controller.GetScheduleByTeacherName(ctx, subject, utils.FormatDate(date))
+		if err != nil {
+			return nil, fmt.Errorf("преподаватель %q: %w", subject, err)
+		}
+
+		return weeklySchedule, nil
+	default:
+		return nil, fmt.Errorf("%w: ожидается group или teacher", errUsage)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	hmtpkErrors "github.com/chazari-x/hmtpk_parser/v2/errors"
+)
+
+func Test_run(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/ru/students/schedule"):
+			http.ServeFile(w, r, filepath.Join("..", "..", "testdata", "group_schedule.html"))
+		case strings.HasPrefix(r.URL.Path, "/ru/teachers/schedule"):
+			http.ServeFile(w, r, filepath.Join("..", "..", "testdata", "teacher_schedule.html"))
+		default:
+			http.ServeFile(w, r, filepath.Join("..", "..", "testdata", "announce_page.html"))
+		}
+	}))
+	defer upstream.Close()
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "groups csv",
+			args: []string{"groups", "--format", "csv"},
+			want: []string{"Название,Идентификатор", "ИС-21,114808"},
+		},
+		{
+			name: "group schedule by label",
+			args: []string{"schedule", "group", "ис-21", "--date", "20.03.2024"},
+			want: []string{"18 марта 2024, Понедельник", "Информатика", "Петрова Анна Сергеевна"},
+		},
+		{
+			name: "group schedule by fuzzy label",
+			args: []string{"schedule", "group", "ис21", "--date", "20.03.2024"},
+			want: []string{"18 марта 2024, Понедельник", "Информатика", "Петрова Анна Сергеевна"},
+		},
+		{
+			name: "teacher schedule by name in parts",
+			args: []string{"schedule", "teacher", "Иванов", "Иван", "Иванович", "--format", "json"},
+			want: []string{`"group": "ПК-31"`},
+		},
+		{
+			name: "teacher schedule by initials",
+			args: []string{"schedule", "teacher", "иванов", "и.и.", "--format", "json"},
+			want: []string{`"group": "ПК-31"`},
+		},
+		{
+			name: "announces",
+			args: []string{"announces", "--page", "2"},
+			want: []string{"Изменение расписания"},
+		},
+		{
+			name:    "group with typo is not guessed",
+			args:    []string{"schedule", "group", "ПК-32"},
+			wantErr: hmtpkErrors.ErrorNotFound,
+		},
+		{
+			name:    "unknown group",
+			args:    []string{"schedule", "group", "XX-99"},
+			wantErr: hmtpkErrors.ErrorNotFound,
+		},
+		{
+			name:    "unknown format",
+			args:    []string{"groups", "--format", "xml"},
+			wantErr: errUsage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := run(context.Background(), append(tt.args, "--base-url", upstream.URL), &out)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("run() output does not contain %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
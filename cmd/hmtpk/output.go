@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+func writeOptions(w io.Writer, format string, options []model.Option) error {
+	if format == formatJSON {
+		return writeJSON(w, options)
+	}
+
+	rows := make([][]string, 0, len(options))
+	for _, option := range options {
+		rows = append(rows, []string{option.Label, option.Value})
+	}
+
+	return writeRows(w, format, []string{"Название", "Идентификатор"}, rows)
+}
+
+func writeSchedule(w io.Writer, format string, weeklySchedule []model.Schedule) error {
+	if format == formatJSON {
+		return writeJSON(w, weeklySchedule)
+	}
+
+	var rows [][]string
+	for _, day := range weeklySchedule {
+		for _, lesson := range day.Lessons {
+			rows = append(rows, []string{
+				day.Title,
+				lesson.Num,
+				lesson.Time,
+				lesson.Name,
+				lesson.Subgroup,
+				strings.TrimSpace(lesson.Location + " " + lesson.Room),
+				lesson.Teacher,
+				lesson.Group,
+			})
+		}
+	}
+
+	return writeRows(w, format, []string{"День", "Пара", "Время", "Предмет", "Подгруппа", "Кабинет", "Преподаватель", "Группа"}, rows)
+}
+
+func writeAnnounces(w io.Writer, format string, announces model.Announces) error {
+	if format == formatJSON {
+		return writeJSON(w, announces)
+	}
+
+	rows := make([][]string, 0, len(announces.Announces))
+	for _, announce := range announces.Announces {
+		rows = append(rows, []string{announce.Date, announce.Title, announce.Path})
+	}
+
+	return writeRows(w, format, []string{"Дата", "Заголовок", "Адрес"}, rows)
+}
+
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(v)
+}
+
+func writeRows(w io.Writer, format string, header []string, rows [][]string) error {
+	if format == formatCSV {
+		writer := csv.NewWriter(w)
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		if err := writer.WriteAll(rows); err != nil {
+			return err
+		}
+
+		return writer.Error()
+	}
+
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, row := range append([][]string{header}, rows...) {
+		if _, err := fmt.Fprintln(writer, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
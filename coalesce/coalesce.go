@@ -0,0 +1,116 @@
+package coalesce
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout наибольшее время выполнения функции, если Group.Timeout не задан
+const DefaultTimeout = time.Second * 30
+
+// Group объединяет одновременные вызовы с одинаковым ключом: функция выполняется один раз,
+// остальные вызовы ждут ее и получают тот же результат. Нулевое значение готово к работе
+type Group struct {
+	// Timeout наибольшее время выполнения функции. Если равен нулю, используется DefaultTimeout
+	Timeout time.Duration
+
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+type call struct {
+	done    chan struct{}
+	val     any
+	err     error
+	dups    int
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// Do выполняет fn, если вызов с ключом key еще не выполняется, иначе ждет результат уже идущего вызова.
+// Функция выполняется с контекстом, отвязанным от ctx отдельного вызова и ограниченным Timeout:
+// отмена ctx завершает ожидание одного вызова, остальные продолжают ждать результат. Когда ожидание
+// прекратили все вызовы, контекст функции отменяется. Результат общий для всех вызовов, изменять его нельзя.
+// shared равен true, если результат получили несколько вызовов
+func (g *Group) Do(ctx context.Context, key string, fn func(ctx context.Context) (any, error)) (v any, shared bool, err error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+
+	c, ok := g.calls[key]
+	if ok {
+		c.dups++
+		c.waiters++
+	} else {
+		timeout := g.Timeout
+		if timeout <= 0 {
+			timeout = DefaultTimeout
+		}
+
+		var fnCtx context.Context
+		c = &call{done: make(chan struct{}), waiters: 1}
+		fnCtx, c.cancel = context.WithTimeout(detached{ctx}, timeout)
+		g.calls[key] = c
+		go g.run(fnCtx, key, c, fn)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.val, c.dups > 0, c.err
+	case <-ctx.Done():
+		g.leave(key, c)
+		return nil, false, ctx.Err()
+	}
+}
+
+// leave снимает вызов с ожидания и отменяет функцию, если ее результат больше никто не ждет.
+// Следующий вызов с тем же ключом запускает функцию заново
+func (g *Group) leave(key string, c *call) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if c.waiters--; c.waiters == 0 {
+		c.cancel()
+		if g.calls[key] == c {
+			delete(g.calls, key)
+		}
+	}
+}
+
+// run выполняет fn и сообщает результат ожидающим вызовам
+func (g *Group) run(ctx context.Context, key string, c *call, fn func(ctx context.Context) (any, error)) {
+	defer c.cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			c.val, c.err = nil, fmt.Errorf("coalesce: function panicked: %v", r)
+		}
+
+		g.mu.Lock()
+		if g.calls[key] == c {
+			delete(g.calls, key)
+		}
+		g.mu.Unlock()
+
+		close(c.done)
+	}()
+
+	c.val, c.err = fn(ctx)
+}
+
+// detached сохраняет значения родительского контекста, но не его отмену и срок (аналог context.WithoutCancel)
+type detached struct {
+	parent context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (detached) Done() <-chan struct{} { return nil }
+
+func (detached) Err() error { return nil }
+
+func (d detached) Value(key any) any { return d.parent.Value(key) }
@@ -0,0 +1,171 @@
+package coalesce
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGroup_Do(t *testing.T) {
+	var (
+		g       Group
+		calls   int32
+		wg      sync.WaitGroup
+		release = make(chan struct{})
+		started = make(chan struct{})
+	)
+
+	fn := func(context.Context) (any, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+		}
+		<-release
+		return "schedule", nil
+	}
+
+	results := make([]any, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			if i > 0 {
+				<-started
+			}
+
+			v, _, err := g.Do(context.Background(), "2024/12:114808", fn)
+			if err != nil {
+				t.Errorf("Do() error = %v", err)
+			}
+			results[i] = v
+		}(i)
+	}
+
+	<-started
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("Do() called fn %d times, want 1", calls)
+	}
+	for i, v := range results {
+		if v != "schedule" {
+			t.Errorf("Do() result %d = %v, want schedule", i, v)
+		}
+	}
+
+	// после завершения вызова ключ освобождается
+	if _, shared, _ := g.Do(context.Background(), "2024/12:114808", func(context.Context) (any, error) { return nil, nil }); shared {
+		t.Errorf("Do() shared = true for a new call")
+	}
+}
+
+func TestGroup_DoWaiterCanceled(t *testing.T) {
+	var g Group
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	go func() {
+		_, _, _ = g.Do(context.Background(), "groups", func(context.Context) (any, error) {
+			close(started)
+			<-release
+			return nil, nil
+		})
+	}()
+	<-started
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := g.Do(ctx, "groups", func(context.Context) (any, error) { return nil, nil }); !errors.Is(err, context.Canceled) {
+		t.Errorf("Do() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestGroup_DoFirstCallerCanceled(t *testing.T) {
+	var g Group
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	fn := func(ctx context.Context) (any, error) {
+		close(started)
+		select {
+		case <-release:
+			return "groups", nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, _, err := g.Do(ctx, "groups", fn)
+		first <- err
+	}()
+	<-started
+
+	second := make(chan any, 1)
+	go func() {
+		v, _, err := g.Do(context.Background(), "groups", fn)
+		if err != nil {
+			t.Errorf("Do() error = %v", err)
+		}
+		second <- v
+	}()
+	for waiting := false; !waiting; {
+		g.mu.Lock()
+		waiting = g.calls["groups"].dups == 1
+		g.mu.Unlock()
+	}
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("Do() error = %v, want %v", err, context.Canceled)
+	}
+
+	close(release)
+	if v := <-second; v != "groups" {
+		t.Errorf("Do() = %v, want groups", v)
+	}
+}
+
+func TestGroup_DoSoleCallerCanceled(t *testing.T) {
+	var g Group
+	started := make(chan struct{})
+	stopped := make(chan error, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	_, _, err := g.Do(ctx, "groups", func(ctx context.Context) (any, error) {
+		close(started)
+		<-ctx.Done()
+		stopped <- ctx.Err()
+		return nil, ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Do() error = %v, want %v", err, context.Canceled)
+	}
+
+	select {
+	case err = <-stopped:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("fn ctx error = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("fn ctx was not canceled after the only caller gave up")
+	}
+
+	// отмененный вызов не мешает следующему вызову с тем же ключом
+	if v, _, err := g.Do(context.Background(), "groups", func(context.Context) (any, error) { return "groups", nil }); err != nil || v != "groups" {
+		t.Errorf("Do() = %v, %v, want groups", v, err)
+	}
+}
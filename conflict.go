@@ -0,0 +1,20 @@
+package hmtpk_parser
+
+import (
+	"context"
+	"time"
+
+	"github.com/chazari-x/hmtpk_parser/v2/conflict"
+)
+
+// CheckConflicts обходит расписания всех преподавателей на неделю, в которую входит date,
+// и ищет в них противоречия: занятый разными преподавателями кабинет, преподавателя
+// в двух кабинетах сразу и группу с несколькими занятиями на одной паре
+func (c *Controller) CheckConflicts(ctx context.Context, date time.Time) (conflict.Report, error) {
+	teachers, failed, err := c.crawlTeachers(ctx, date)
+	if err != nil {
+		return conflict.Report{}, err
+	}
+
+	return conflict.Report{Conflicts: conflict.Check(teachers), Incomplete: failed}, nil
+}
@@ -0,0 +1,150 @@
+package conflict
+
+import (
+	"sort"
+	"time"
+
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+	"github.com/chazari-x/hmtpk_parser/v2/rooms"
+)
+
+// Type вид противоречия в расписании
+type Type string
+
+const (
+	// Room в одном кабинете на одной паре занимаются разные преподаватели
+	Room Type = "room"
+	// Teacher преподаватель на одной паре ведет занятия в разных кабинетах
+	Teacher Type = "teacher"
+	// Group у группы на одной паре несколько разных занятий без деления на подгруппы
+	Group Type = "group"
+)
+
+// Conflict противоречие на одной паре
+type Conflict struct {
+	Type Type      `json:"type"`
+	Date time.Time `json:"date"`
+	Pair int       `json:"pair"`
+	// Subject кабинет, преподаватель или группа, к которой относится противоречие
+	Subject string         `json:"subject"`
+	Lessons []model.Lesson `json:"lessons"`
+}
+
+// Report результат проверки расписания на неделю
+type Report struct {
+	Conflicts []Conflict `json:"conflicts"`
+	// Incomplete преподаватели, расписание которых получить не удалось и которые не проверены
+	Incomplete []string `json:"incomplete,omitempty"`
+}
+
+// Check проверяет расписания преподавателей на неделю, ключ - ФИО преподавателя.
+// Кабинет и пара определяются так же, как в rooms.Index. Пары без номера не проверяются. Противоречия возвращаются по порядку даты, пары, вида и предмета
+func Check(teachers map[string][]model.Schedule) []Conflict {
+	var (
+		dates    = make(map[string]time.Time)
+		busy     = make(map[rooms.Slot]map[string][]model.Lesson)
+		teaching = make(map[rooms.Slot]map[string][]model.Lesson)
+		groups   = make(map[rooms.Slot]map[string][]model.Lesson)
+	)
+
+	for teacher, weeklySchedule := range teachers {
+		for _, day := range weeklySchedule {
+			if day.Date.IsZero() {
+				continue
+			}
+
+			for _, lesson := range day.Lessons {
+				if lesson.Pair == 0 {
+					continue
+				}
+
+				if lesson.Teacher == "" {
+					lesson.Teacher = teacher
+				}
+
+				key := rooms.SlotOf(day.Date, lesson.Pair)
+				dates[key.Day] = day.Date
+				if room := rooms.RoomOf(lesson); room.Name != "" {
+					add(busy, key, room.String(), lesson)
+					add(teaching, key, lesson.Teacher, lesson)
+				}
+				if lesson.Group != "" && lesson.Subgroup == "" {
+					add(groups, key, lesson.Group, lesson)
+				}
+			}
+		}
+	}
+
+	var conflicts []Conflict
+	report := func(t Type, slots map[rooms.Slot]map[string][]model.Lesson, conflicting func([]model.Lesson) bool) {
+		for key, subjects := range slots {
+			for subject, lessons := range subjects {
+				if conflicting(lessons) {
+					conflicts = append(conflicts, Conflict{
+						Type:    t,
+						Date:    dates[key.Day],
+						Pair:    key.Pair,
+						Subject: subject,
+						Lessons: lessons,
+					})
+				}
+			}
+		}
+	}
+
+	report(Room, busy, func(lessons []model.Lesson) bool {
+		return distinct(lessons, func(l model.Lesson) string { return l.Teacher }) > 1
+	})
+	report(Teacher, teaching, func(lessons []model.Lesson) bool {
+		return distinct(lessons, roomName) > 1
+	})
+	report(Group, groups, func(lessons []model.Lesson) bool {
+		return distinct(lessons, func(l model.Lesson) string {
+			return l.Name + "\x00" + l.Teacher + "\x00" + roomName(l)
+		}) > 1
+	})
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		a, b := conflicts[i], conflicts[j]
+		switch {
+		case !a.Date.Equal(b.Date):
+			return a.Date.Before(b.Date)
+		case a.Pair != b.Pair:
+			return a.Pair < b.Pair
+		case a.Type != b.Type:
+			return a.Type < b.Type
+		default:
+			return a.Subject < b.Subject
+		}
+	})
+
+	for _, c := range conflicts {
+		sort.SliceStable(c.Lessons, func(i, j int) bool {
+			return c.Lessons[i].Teacher < c.Lessons[j].Teacher
+		})
+	}
+
+	return conflicts
+}
+
+func add(slots map[rooms.Slot]map[string][]model.Lesson, key rooms.Slot, subject string, lesson model.Lesson) {
+	if slots[key] == nil {
+		slots[key] = make(map[string][]model.Lesson)
+	}
+	slots[key][subject] = append(slots[key][subject], lesson)
+}
+
+// distinct возвращает количество различных значений key среди пар
+func distinct(lessons []model.Lesson, key func(model.Lesson) string) int {
+	values := make(map[string]bool)
+	for _, lesson := range lessons {
+		values[key(lesson)] = true
+	}
+
+	return len(values)
+}
+
+// roomName возвращает кабинет пары вместе с адресом, например "Гагарина, 1 -205"
+func roomName(lesson model.Lesson) string {
+	return rooms.RoomOf(lesson).String()
+}
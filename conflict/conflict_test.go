@@ -0,0 +1,91 @@
+package conflict
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+)
+
+func TestCheck(t *testing.T) {
+	monday := time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)
+	day := func(lessons ...model.Lesson) []model.Schedule {
+		return []model.Schedule{{Date: monday, Lessons: lessons}}
+	}
+
+	tests := []struct {
+		name     string
+		teachers map[string][]model.Schedule
+		want     []Type
+		subjects []string
+	}{
+		{
+			name: "room booked twice",
+			teachers: map[string][]model.Schedule{
+				"Иванов":  day(model.Lesson{Pair: 1, Name: "Математика", Group: "ИС-21", Room: "-205", Location: "Гагарина, 1"}),
+				"Петрова": day(model.Lesson{Pair: 1, Name: "Физика", Group: "ИС-22", Room: "-205", Location: "Гагарина, 1"}),
+			},
+			want:     []Type{Room},
+			subjects: []string{"Гагарина, 1 -205"},
+		},
+		{
+			name: "teacher in two places",
+			teachers: map[string][]model.Schedule{
+				"Иванов": day(
+					model.Lesson{Pair: 2, Name: "Математика", Group: "ИС-21", Room: "-205", Location: "Гагарина, 1"},
+					model.Lesson{Pair: 2, Name: "Математика", Group: "ИС-22", Room: "-12", Location: "Мира, 15"},
+				),
+			},
+			want:     []Type{Teacher},
+			subjects: []string{"Иванов"},
+		},
+		{
+			name: "group with two lessons",
+			teachers: map[string][]model.Schedule{
+				"Иванов":  day(model.Lesson{Pair: 3, Name: "Математика", Group: "ИС-21", Room: "-205", Location: "Гагарина, 1"}),
+				"Петрова": day(model.Lesson{Pair: 3, Name: "Физика", Group: "ИС-21", Room: "-207", Location: "Гагарина, 1"}),
+			},
+			want:     []Type{Group},
+			subjects: []string{"ИС-21"},
+		},
+		{
+			name: "subgroups and combined groups",
+			teachers: map[string][]model.Schedule{
+				"Иванов": day(
+					model.Lesson{Pair: 1, Name: "Информатика", Group: "ИС-21", Subgroup: "1", Room: "-205", Location: "Гагарина, 1"},
+					model.Lesson{Pair: 2, Name: "Математика", Group: "ИС-21", Room: "-205", Location: "Гагарина, 1"},
+					model.Lesson{Pair: 2, Name: "Математика", Group: "ИС-22", Room: "-205", Location: "Гагарина, 1"},
+				),
+				"Петрова": day(
+					model.Lesson{Pair: 1, Name: "Информатика", Group: "ИС-21", Subgroup: "2", Room: "-207", Location: "Гагарина, 1"},
+					model.Lesson{Name: "Консультация", Group: "ИС-21", Room: "-205", Location: "Гагарина, 1"},
+				),
+			},
+		},
+		{
+			name: "address without room",
+			teachers: map[string][]model.Schedule{
+				"Иванов":  day(model.Lesson{Pair: 4, Name: "Физкультура", Group: "ИС-21", Location: "Гагарина, 1"}),
+				"Петрова": day(model.Lesson{Pair: 4, Name: "Физкультура", Group: "ИС-22", Location: "Гагарина, 1"}),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var types []Type
+			var subjects []string
+			for _, c := range Check(tt.teachers) {
+				types = append(types, c.Type)
+				subjects = append(subjects, c.Subject)
+				if len(c.Lessons) < 2 {
+					t.Errorf("Check() conflict %+v has less than two lessons", c)
+				}
+			}
+
+			if !reflect.DeepEqual(types, tt.want) || !reflect.DeepEqual(subjects, tt.subjects) {
+				t.Errorf("Check() = %v %v, want %v %v", types, subjects, tt.want, tt.subjects)
+			}
+		})
+	}
+}
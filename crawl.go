@@ -0,0 +1,134 @@
+package hmtpk_parser
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chazari-x/hmtpk_parser/v2/crawler"
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+	"github.com/chazari-x/hmtpk_parser/v2/schedule"
+	"github.com/chazari-x/hmtpk_parser/v2/utils"
+)
+
+// CrawlSchedules получает расписания всех групп или всех преподавателей на неделю, в которую входит date,
+// например чтобы заранее заполнить кэш. Ключ результата и Failure.Key - название группы или ФИО преподавателя.
+// Если crawler равен nil, используется crawler.New с WithConcurrency воркерами
+func (c *Controller) CrawlSchedules(ctx context.Context, kind schedule.Kind, date time.Time, cr *crawler.Crawler) (map[string][]model.Schedule, crawler.Summary, error) {
+	adapter, err := c.adapter(kind)
+	if err != nil {
+		return nil, crawler.Summary{}, err
+	}
+
+	options, err := adapter.GetOptions(ctx)
+	if err != nil {
+		return nil, crawler.Summary{}, err
+	}
+
+	var (
+		mu        sync.Mutex
+		schedules = make(map[string][]model.Schedule, len(options))
+		values    = make(map[string]string, len(options))
+		keys      = make([]string, 0, len(options))
+		day       = utils.FormatDate(date.In(utils.Location))
+	)
+	for _, option := range options {
+		// пункты-заглушки вроде "Выберите группу" не обходятся
+		if option.Value == "0" || option.Value == "" {
+			continue
+		}
+
+		if _, ok := values[option.Label]; !ok {
+			keys = append(keys, option.Label)
+		}
+		values[option.Label] = option.Value
+	}
+
+	summary := c.crawler(cr).Run(ctx, keys, func(ctx context.Context, key string) error {
+		weeklySchedule, err := c.getSchedule(ctx, values[key], day, adapter)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		schedules[key] = weeklySchedule
+		mu.Unlock()
+
+		return nil
+	})
+
+	return schedules, summary, ctx.Err()
+}
+
+// CrawlAnnounces получает страницы объявлений с первой по pages. Если pages не больше нуля,
+// получаются все страницы до последней. Неполученные страницы в результате пустые
+func (c *Controller) CrawlAnnounces(ctx context.Context, pages int, cr *crawler.Crawler) ([]model.Announces, crawler.Summary, error) {
+	cr = c.crawler(cr)
+
+	var first model.Announces
+	summary := cr.Run(ctx, []string{"1"}, func(ctx context.Context, _ string) (err error) {
+		first, err = c.GetAnnounces(ctx, 1)
+		return err
+	})
+	if summary.Err() != nil {
+		return nil, summary, summary.Err()
+	}
+
+	if pages <= 0 || (first.LastPage > 0 && pages > first.LastPage) {
+		pages = first.LastPage
+	}
+	if pages < 1 {
+		pages = 1
+	}
+
+	announces := make([]model.Announces, pages)
+	announces[0] = first
+
+	keys := make([]string, 0, pages-1)
+	for page := 2; page <= pages; page++ {
+		keys = append(keys, strconv.Itoa(page))
+	}
+
+	rest := cr.Run(ctx, keys, func(ctx context.Context, key string) error {
+		page, _ := strconv.Atoi(key)
+		result, err := c.GetAnnounces(ctx, page)
+		if err != nil {
+			return err
+		}
+
+		announces[page-1] = result
+		return nil
+	})
+
+	summary.Total += rest.Total
+	summary.Succeeded += rest.Succeeded
+	summary.Failures = append(summary.Failures, rest.Failures...)
+	summary.Elapsed += rest.Elapsed
+
+	return announces, summary, ctx.Err()
+}
+
+// crawler возвращает cr или Crawler по умолчанию с WithConcurrency воркерами
+func (c *Controller) crawler(cr *crawler.Crawler) *crawler.Crawler {
+	if cr != nil {
+		return cr
+	}
+
+	return crawler.New(crawler.WithWorkers(c.concurrency))
+}
+
+// crawlTeachers получает расписания всех преподавателей на неделю, в которую входит date.
+// Вторым значением возвращаются преподаватели, расписание которых получить не удалось
+func (c *Controller) crawlTeachers(ctx context.Context, date time.Time) (map[string][]model.Schedule, []string, error) {
+	teachers, summary, err := c.CrawlSchedules(ctx, schedule.Teacher, date, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = summary.Err(); err != nil {
+		c.log.Error(err)
+	}
+
+	return teachers, summary.Keys(), nil
+}
@@ -0,0 +1,73 @@
+package hmtpk_parser
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/chazari-x/hmtpk_parser/v2/crawler"
+	"github.com/chazari-x/hmtpk_parser/v2/schedule"
+	"github.com/sirupsen/logrus"
+)
+
+func TestController_CrawlSchedules(t *testing.T) {
+	srv := newTestServer(t)
+	c := NewController(nil, logrus.StandardLogger(), WithBaseURL(srv.URL), WithHTTPClient(srv.Client()))
+
+	var done int
+	cr := crawler.New(crawler.WithWorkers(2), crawler.WithProgress(func(p crawler.Progress) { done = p.Done }))
+
+	schedules, summary, err := c.CrawlSchedules(context.Background(), schedule.Group, time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC), cr)
+	if err != nil {
+		t.Fatalf("CrawlSchedules() error = %v", err)
+	}
+
+	var groups []string
+	for group, weeklySchedule := range schedules {
+		groups = append(groups, group)
+		if len(weeklySchedule) != 7 {
+			t.Errorf("CrawlSchedules() %s got %d days, want 7", group, len(weeklySchedule))
+		}
+	}
+	sort.Strings(groups)
+
+	if want := []string{"ДО-11", "ИС-21", "ИС-22", "ПК-31"}; !reflect.DeepEqual(groups, want) {
+		t.Errorf("CrawlSchedules() groups = %v, want %v", groups, want)
+	}
+	if summary.Total != 4 || summary.Succeeded != 4 || done != 4 {
+		t.Errorf("CrawlSchedules() summary = %+v, progress = %d, want 4 succeeded", summary, done)
+	}
+}
+
+func TestController_CrawlAnnounces(t *testing.T) {
+	srv := newTestServer(t)
+	c := NewController(nil, logrus.StandardLogger(), WithBaseURL(srv.URL), WithHTTPClient(srv.Client()))
+
+	tests := []struct {
+		name  string
+		pages int
+		want  int
+	}{
+		{name: "first pages", pages: 3, want: 3},
+		{name: "all pages", pages: 0, want: 70},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			announces, summary, err := c.CrawlAnnounces(context.Background(), tt.pages, nil)
+			if err != nil || summary.Err() != nil {
+				t.Fatalf("CrawlAnnounces() error = %v, %v", err, summary.Err())
+			}
+
+			if len(announces) != tt.want || summary.Succeeded != tt.want {
+				t.Fatalf("CrawlAnnounces() got %d pages, summary = %+v, want %d", len(announces), summary, tt.want)
+			}
+			for i, page := range announces {
+				if len(page.Announces) == 0 {
+					t.Errorf("CrawlAnnounces() page %d is empty", i+1)
+				}
+			}
+		})
+	}
+}
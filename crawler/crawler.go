@@ -0,0 +1,213 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chazari-x/hmtpk_parser/v2/ratelimit"
+)
+
+// DefaultWorkers количество задач, которые Crawler выполняет одновременно по умолчанию
+const DefaultWorkers = 4
+
+// Job задача обхода по ключу: номер группы, ФИО преподавателя или номер страницы объявлений
+type Job func(ctx context.Context, key string) error
+
+// Progress состояние обхода после завершения очередной задачи
+type Progress struct {
+	Key string
+	Err error
+	// Done количество завершенных задач, включая неудачные
+	Done   int
+	Failed int
+	Total  int
+}
+
+// Failure неудачная задача
+type Failure struct {
+	Key string `json:"key"`
+	Err error  `json:"-"`
+}
+
+// Summary итог обхода
+type Summary struct {
+	Total     int           `json:"total"`
+	Succeeded int           `json:"succeeded"`
+	Failures  []Failure     `json:"failures,omitempty"`
+	Elapsed   time.Duration `json:"elapsed"`
+}
+
+// Err возвращает ошибки всех неудачных задач или nil, если неудачных задач нет
+func (s Summary) Err() error {
+	errs := make([]error, 0, len(s.Failures))
+	for _, failure := range s.Failures {
+		errs = append(errs, fmt.Errorf("%s: %w", failure.Key, failure.Err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Keys возвращает ключи неудачных задач
+func (s Summary) Keys() []string {
+	var keys []string
+	for _, failure := range s.Failures {
+		keys = append(keys, failure.Key)
+	}
+
+	return keys
+}
+
+// Option настраивает Crawler при создании
+type Option func(*Crawler)
+
+// WithWorkers задает количество задач, которые выполняются одновременно
+func WithWorkers(workers int) Option {
+	return func(c *Crawler) {
+		c.workers = workers
+	}
+}
+
+// WithLimiter задает ограничение частоты запуска задач. Один ratelimit.Limiter можно передать
+// нескольким Crawler, чтобы ограничение было общим
+func WithLimiter(limiter *ratelimit.Limiter) Option {
+	return func(c *Crawler) {
+		c.limiter = limiter
+	}
+}
+
+// WithRate задает ограничение в rps задач в секунду
+func WithRate(rps float64) Option {
+	return func(c *Crawler) {
+		c.limiter = ratelimit.New(rps, 1)
+	}
+}
+
+// WithTimeout задает наибольшее время выполнения одной задачи
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Crawler) {
+		c.timeout = timeout
+	}
+}
+
+// WithProgress задает функцию, которая вызывается после каждой задачи.
+// Вызовы не пересекаются, поэтому функции не нужна своя синхронизация
+func WithProgress(progress func(Progress)) Option {
+	return func(c *Crawler) {
+		c.progress = progress
+	}
+}
+
+// Crawler выполняет задачи обхода пулом из нескольких воркеров с общим ограничением частоты
+type Crawler struct {
+	workers  int
+	limiter  *ratelimit.Limiter
+	timeout  time.Duration
+	progress func(Progress)
+}
+
+// New создает Crawler. По умолчанию DefaultWorkers воркеров, без ограничения частоты и тайм-аута
+func New(opts ...Option) *Crawler {
+	c := &Crawler{workers: DefaultWorkers}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.workers < 1 {
+		c.workers = 1
+	}
+
+	return c
+}
+
+// Run выполняет job для каждого ключа и возвращает итог. Ошибка задачи не останавливает обход,
+// а при отмене ctx задачи, которые еще не начались, считаются неудачными с ошибкой ctx.Err()
+func (c *Crawler) Run(ctx context.Context, keys []string, job Job) Summary {
+	start := time.Now()
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		summary = Summary{Total: len(keys)}
+		errs    = make([]error, len(keys))
+		indexes = make(chan int)
+	)
+
+	finish := func(i int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		errs[i] = err
+		if err == nil {
+			summary.Succeeded++
+		} else {
+			summary.Failures = append(summary.Failures, Failure{Key: keys[i], Err: err})
+		}
+
+		if c.progress != nil {
+			c.progress(Progress{
+				Key:    keys[i],
+				Err:    err,
+				Done:   summary.Succeeded + len(summary.Failures),
+				Failed: len(summary.Failures),
+				Total:  summary.Total,
+			})
+		}
+	}
+
+	for w := 0; w < c.workers && w < len(keys); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				finish(i, c.do(ctx, keys[i], job))
+			}
+		}()
+	}
+
+	for i := range keys {
+		if ctx.Err() != nil {
+			finish(i, ctx.Err())
+			continue
+		}
+
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			finish(i, ctx.Err())
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	// неудачные задачи перечисляются в порядке ключей, а не завершения
+	summary.Failures = summary.Failures[:0]
+	for i, err := range errs {
+		if err != nil {
+			summary.Failures = append(summary.Failures, Failure{Key: keys[i], Err: err})
+		}
+	}
+	if len(summary.Failures) == 0 {
+		summary.Failures = nil
+	}
+	summary.Elapsed = time.Since(start)
+
+	return summary
+}
+
+func (c *Crawler) do(ctx context.Context, key string, job Job) error {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	return job(ctx, key)
+}
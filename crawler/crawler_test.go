@@ -0,0 +1,81 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCrawler_Run(t *testing.T) {
+	errFailed := errors.New("failed")
+	keys := []string{"1", "2", "3", "4", "5", "6"}
+
+	var (
+		running, peak int32
+		progress      []Progress
+	)
+	c := New(
+		WithWorkers(2),
+		WithTimeout(20*time.Millisecond),
+		WithProgress(func(p Progress) { progress = append(progress, p) }),
+	)
+
+	summary := c.Run(context.Background(), keys, func(ctx context.Context, key string) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+
+		switch key {
+		case "2":
+			return errFailed
+		case "5":
+			<-ctx.Done()
+			return ctx.Err()
+		}
+
+		time.Sleep(time.Millisecond)
+		return nil
+	})
+
+	if peak > 2 {
+		t.Errorf("Run() ran %d jobs at once, want at most 2", peak)
+	}
+	if summary.Total != 6 || summary.Succeeded != 4 {
+		t.Errorf("Run() summary = %+v, want 4 of 6 succeeded", summary)
+	}
+	if got := summary.Keys(); !reflect.DeepEqual(got, []string{"2", "5"}) {
+		t.Errorf("Run() failed keys = %v, want [2 5]", got)
+	}
+	if !errors.Is(summary.Err(), errFailed) || !errors.Is(summary.Err(), context.DeadlineExceeded) {
+		t.Errorf("Run() Err() = %v, want %v and %v", summary.Err(), errFailed, context.DeadlineExceeded)
+	}
+	if len(progress) != 6 || progress[5].Done != 6 || progress[5].Failed != 2 {
+		t.Errorf("Run() progress = %+v, want 6 calls ending with 2 failed", progress)
+	}
+}
+
+func TestCrawler_RunCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var calls int32
+	summary := New(WithWorkers(1), WithRate(1000)).Run(ctx, []string{"1", "2", "3"}, func(ctx context.Context, key string) error {
+		atomic.AddInt32(&calls, 1)
+		cancel()
+		return nil
+	})
+
+	if calls != 1 || summary.Succeeded != 1 || len(summary.Failures) != 2 {
+		t.Errorf("Run() calls = %d, summary = %+v, want 1 call and 2 failures", calls, summary)
+	}
+	if summary.Err() == nil || !errors.Is(summary.Failures[0].Err, context.Canceled) {
+		t.Errorf("Run() failures = %+v, want %v", summary.Failures, context.Canceled)
+	}
+}
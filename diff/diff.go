@@ -0,0 +1,198 @@
+package diff
+
+import (
+	"sort"
+	"time"
+
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+)
+
+// Type вид изменения пары
+type Type string
+
+const (
+	Added    Type = "added"
+	Removed  Type = "removed"
+	Modified Type = "modified"
+)
+
+// Поля пары, которые перечисляются в Change.Fields
+const (
+	FieldTime     = "time"
+	FieldName     = "name"
+	FieldRoom     = "room"
+	FieldLocation = "location"
+	FieldTeacher  = "teacher"
+	FieldGroup    = "group"
+	FieldSubgroup = "subgroup"
+)
+
+// Change изменение одной пары. Для Added заполнено только New, для Removed - только Old
+type Change struct {
+	Type Type          `json:"type"`
+	Old  *model.Lesson `json:"old,omitempty"`
+	New  *model.Lesson `json:"new,omitempty"`
+	// Fields измененные поля пары для Modified
+	Fields []string `json:"fields,omitempty"`
+}
+
+// Day изменения расписания за один день
+type Day struct {
+	Date    time.Time `json:"date"`
+	Title   string    `json:"title"`
+	Changes []Change  `json:"changes"`
+}
+
+// Compare сравнивает предыдущее расписание previous с текущим current одной группы или преподавателя
+// и возвращает изменения по дням.
+// Пары сопоставляются сначала полностью, затем по названию, группе и подгруппе (перенос пары,
+// смена кабинета или преподавателя), затем по времени и подгруппе (замена предмета).
+// Несопоставленные пары считаются удаленными или добавленными. Если изменений нет, возвращается nil
+func Compare(previous, current []model.Schedule) []Day {
+	var days []Day
+	for _, key := range dayKeys(previous, current) {
+		previousDay, currentDay := findDay(previous, key), findDay(current, key)
+		changes := compareLessons(previousDay.Lessons, currentDay.Lessons)
+		if len(changes) == 0 {
+			continue
+		}
+
+		day := Day{Date: currentDay.Date, Title: currentDay.Title, Changes: changes}
+		if day.Date.IsZero() && day.Title == "" {
+			day.Date, day.Title = previousDay.Date, previousDay.Title
+		}
+
+		days = append(days, day)
+	}
+
+	return days
+}
+
+// dayKey возвращает ключ, по которому сопоставляются дни двух расписаний
+func dayKey(day model.Schedule) string {
+	if day.Date.IsZero() {
+		return day.Title
+	}
+
+	return day.Date.Format("2006-01-02")
+}
+
+// dayKeys возвращает ключи дней обоих расписаний по порядку, сначала дни нового расписания
+func dayKeys(previous, current []model.Schedule) (keys []string) {
+	seen := make(map[string]bool)
+	for _, weeklySchedule := range [][]model.Schedule{current, previous} {
+		for _, day := range weeklySchedule {
+			if key := dayKey(day); !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	return
+}
+
+func findDay(weeklySchedule []model.Schedule, key string) model.Schedule {
+	for _, day := range weeklySchedule {
+		if dayKey(day) == key {
+			return day
+		}
+	}
+
+	return model.Schedule{}
+}
+
+func compareLessons(previous, current []model.Lesson) []Change {
+	previousLeft := make([]bool, len(previous))
+	currentLeft := make([]bool, len(current))
+	for i := range previousLeft {
+		previousLeft[i] = true
+	}
+	for i := range currentLeft {
+		currentLeft[i] = true
+	}
+
+	var changes []Change
+	match := func(same func(a, b model.Lesson) bool) {
+		for i := range previous {
+			if !previousLeft[i] {
+				continue
+			}
+
+			for j := range current {
+				if !currentLeft[j] || !same(previous[i], current[j]) {
+					continue
+				}
+
+				previousLeft[i], currentLeft[j] = false, false
+				if fields := changedFields(previous[i], current[j]); len(fields) != 0 {
+					changes = append(changes, Change{Type: Modified, Old: &previous[i], New: &current[j], Fields: fields})
+				}
+
+				break
+			}
+		}
+	}
+
+	match(func(a, b model.Lesson) bool {
+		return len(changedFields(a, b)) == 0
+	})
+	match(func(a, b model.Lesson) bool {
+		return a.Name == b.Name && a.Group == b.Group && a.Subgroup == b.Subgroup
+	})
+	match(func(a, b model.Lesson) bool {
+		return a.Num == b.Num && a.Time == b.Time && a.Subgroup == b.Subgroup
+	})
+
+	for i := range previous {
+		if previousLeft[i] {
+			changes = append(changes, Change{Type: Removed, Old: &previous[i]})
+		}
+	}
+	for j := range current {
+		if currentLeft[j] {
+			changes = append(changes, Change{Type: Added, New: &current[j]})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].lesson().Pair < changes[j].lesson().Pair
+	})
+
+	return changes
+}
+
+// lesson возвращает пару после изменения, а для удаленной пары - до изменения
+func (c Change) lesson() *model.Lesson {
+	if c.New != nil {
+		return c.New
+	}
+
+	return c.Old
+}
+
+func changedFields(a, b model.Lesson) (fields []string) {
+	if a.Num != b.Num || a.Time != b.Time {
+		fields = append(fields, FieldTime)
+	}
+	if a.Name != b.Name {
+		fields = append(fields, FieldName)
+	}
+	if a.Room != b.Room {
+		fields = append(fields, FieldRoom)
+	}
+	if a.Location != b.Location {
+		fields = append(fields, FieldLocation)
+	}
+	if a.Teacher != b.Teacher {
+		fields = append(fields, FieldTeacher)
+	}
+	if a.Group != b.Group {
+		fields = append(fields, FieldGroup)
+	}
+	if a.Subgroup != b.Subgroup {
+		fields = append(fields, FieldSubgroup)
+	}
+
+	return
+}
@@ -0,0 +1,88 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+	"github.com/chazari-x/hmtpk_parser/v2/schedule/group"
+)
+
+func loadSchedule(t *testing.T) []model.Schedule {
+	t.Helper()
+
+	page, err := os.Open(filepath.Join("..", "testdata", "group_schedule.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = page.Close()
+	}()
+
+	weeklySchedule, err := group.ParseSchedule(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return weeklySchedule
+}
+
+func TestCompare(t *testing.T) {
+	previous := loadSchedule(t)
+	if got := Compare(previous, loadSchedule(t)); got != nil {
+		t.Fatalf("Compare() of equal schedules = %v, want nil", got)
+	}
+
+	current := loadSchedule(t)
+	// понедельник: сменился кабинет математики
+	current[0].Lessons[0].Room = "210"
+	// вторник: математика перенесена с 3 пары на 4
+	current[1].Lessons[1].Num, current[1].Lessons[1].Time, current[1].Lessons[1].Pair = "4", "13:50-15:20", 4
+	// среда: литература отменена
+	current[2].Lessons = append(current[2].Lessons[:1:1], current[2].Lessons[2:]...)
+	// четверг: добавлена пара
+	current[3].Lessons = []model.Lesson{{Num: "1", Pair: 1, Time: "08:30-10:00", Name: "Физика", Room: "401"}}
+	// пятница: вторая пара заменена другим предметом
+	current[4].Lessons[1].Name, current[4].Lessons[1].Teacher = "Физика", "Соколов Игорь Павлович"
+
+	type want struct {
+		typ    Type
+		name   string
+		fields []string
+	}
+	tests := []struct {
+		name string
+		day  int
+		want []want
+	}{
+		{name: "room", day: 0, want: []want{{Modified, "Математика", []string{FieldRoom}}}},
+		{name: "moved", day: 1, want: []want{{Modified, "Математика", []string{FieldTime}}}},
+		{name: "removed", day: 2, want: []want{{Removed, "Литература", nil}}},
+		{name: "added", day: 3, want: []want{{Added, "Физика", nil}}},
+		{name: "replaced", day: 4, want: []want{{Modified, "Физика", []string{FieldName, FieldTeacher}}}},
+	}
+
+	days := Compare(previous, current)
+	if len(days) != len(tests) {
+		t.Fatalf("Compare() days = %d, want %d: %+v", len(days), len(tests), days)
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			day := days[i]
+			if !day.Date.Equal(previous[tt.day].Date) {
+				t.Errorf("Compare() date = %v, want %v", day.Date, previous[tt.day].Date)
+			}
+			if len(day.Changes) != len(tt.want) {
+				t.Fatalf("Compare() changes = %+v, want %+v", day.Changes, tt.want)
+			}
+			for j, change := range day.Changes {
+				if change.Type != tt.want[j].typ || change.lesson().Name != tt.want[j].name || !reflect.DeepEqual(change.Fields, tt.want[j].fields) {
+					t.Errorf("Compare() change = %s %s %v, want %+v", change.Type, change.lesson().Name, change.Fields, tt.want[j])
+				}
+			}
+		})
+	}
+}
@@ -2,9 +2,72 @@ package errors
 
 import (
 	errs "errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chazari-x/hmtpk_parser/v2/model"
 )
 
 var (
 	ErrorBadResponse = errs.New("Неверный ответ от https://hmtpk.ru")
 	ErrorBadRequest  = errs.New("Неверный запрос")
+	ErrorNotFound    = errs.New("Не найдено")
+	ErrorAmbiguous   = errs.New("Найдено несколько подходящих вариантов")
+	ErrorCircuitOpen = errs.New("Сайт https://hmtpk.ru недоступен, запросы временно не выполняются")
+	ErrorStarted     = errs.New("Уже запущено")
 )
+
+// UpstreamError запрос к сайту колледжа не удался после всех попыток.
+// Если сайт ответил, errors.Is(err, ErrorBadResponse) возвращает true,
+// если запрос не дошел до сайта, UpstreamError оборачивает сетевую ошибку Err
+type UpstreamError struct {
+	// StatusCode код последнего ответа или 0, если ответа не было
+	StatusCode int
+	URL        string
+	Attempts   int
+	// RetryAfter задержка из заголовка Retry-After последнего ответа
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *UpstreamError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s: %d %s (%s, попыток: %d)", ErrorBadResponse, e.StatusCode, http.StatusText(e.StatusCode), e.URL, e.Attempts)
+	}
+
+	return fmt.Sprintf("Запрос к %s не удался (попыток: %d): %v", e.URL, e.Attempts, e.Err)
+}
+
+func (e *UpstreamError) Unwrap() []error {
+	var wrapped []error
+	if e.StatusCode != 0 {
+		wrapped = append(wrapped, ErrorBadResponse)
+	}
+	if e.Err != nil {
+		wrapped = append(wrapped, e.Err)
+	}
+
+	return wrapped
+}
+
+// NotFoundError точного совпадения нет, но есть варианты, похожие с опечаткой, которые вызывающий
+// может предложить пользователю. errors.Is(err, ErrorNotFound) возвращает true
+type NotFoundError struct {
+	Query       string
+	Suggestions []model.Option
+}
+
+func (e *NotFoundError) Error() string {
+	labels := make([]string, 0, len(e.Suggestions))
+	for _, suggestion := range e.Suggestions {
+		labels = append(labels, suggestion.Label)
+	}
+
+	return fmt.Sprintf("%s: %q, возможно имелось в виду: %s", ErrorNotFound, e.Query, strings.Join(labels, ", "))
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return ErrorNotFound
+}
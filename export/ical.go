@@ -0,0 +1,171 @@
+package export
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+	"github.com/chazari-x/hmtpk_parser/v2/utils"
+)
+
+const (
+	// timezone идентификатор часового пояса колледжа в календаре
+	timezone = "Asia/Yekaterinburg"
+	// maxLineLength максимальная длина строки календаря в октетах без CRLF (RFC 5545, 3.1)
+	maxLineLength = 75
+
+	localLayout = "20060102T150405"
+	utcLayout   = "20060102T150405Z"
+)
+
+// now используется для DTSTAMP и подменяется в тестах
+var now = time.Now
+
+// ICalendar записывает расписание в формате iCalendar (RFC 5545): по одному VEVENT на каждую пару.
+// name - название календаря, обычно название группы или ФИО преподавателя. UID событий зависит
+// только от name, начала пары, группы и подгруппы, поэтому повторный импорт обновляет события,
+// а не создает дубликаты. Пары без времени пропускаются
+func ICalendar(w io.Writer, name string, weeklySchedule []model.Schedule) error {
+	b := bufio.NewWriter(w)
+	stamp := now().UTC().Format(utcLayout)
+
+	writeLine(b, "BEGIN:VCALENDAR")
+	writeLine(b, "VERSION:2.0")
+	writeLine(b, "PRODID:-//chazari-x//hmtpk_parser//RU")
+	writeLine(b, "CALSCALE:GREGORIAN")
+	writeLine(b, "METHOD:PUBLISH")
+	writeLine(b, "X-WR-CALNAME:"+escape(name))
+	writeLine(b, "X-WR-TIMEZONE:"+timezone)
+
+	// Переход на летнее время в поясе отменен в 2011 году, поэтому достаточно одного STANDARD
+	writeLine(b, "BEGIN:VTIMEZONE")
+	writeLine(b, "TZID:"+timezone)
+	writeLine(b, "BEGIN:STANDARD")
+	writeLine(b, "DTSTART:19700101T000000")
+	writeLine(b, "TZOFFSETFROM:+0500")
+	writeLine(b, "TZOFFSETTO:+0500")
+	writeLine(b, "TZNAME:+05")
+	writeLine(b, "END:STANDARD")
+	writeLine(b, "END:VTIMEZONE")
+
+	for _, day := range weeklySchedule {
+		for _, lesson := range day.Lessons {
+			if lesson.Start.IsZero() || lesson.End.IsZero() {
+				continue
+			}
+
+			writeLine(b, "BEGIN:VEVENT")
+			writeLine(b, "UID:"+uid(name, lesson))
+			writeLine(b, "DTSTAMP:"+stamp)
+			writeLine(b, "DTSTART;TZID="+timezone+":"+lesson.Start.In(utils.Location).Format(localLayout))
+			writeLine(b, "DTEND;TZID="+timezone+":"+lesson.End.In(utils.Location).Format(localLayout))
+			writeLine(b, "SUMMARY:"+escape(lesson.Name))
+			if location := joinNotEmpty(", ", lesson.Location, lesson.Room); location != "" {
+				writeLine(b, "LOCATION:"+escape(location))
+			}
+			if description := description(lesson); description != "" {
+				writeLine(b, "DESCRIPTION:"+escape(description))
+			}
+			if href := uri(day.Href); href != "" {
+				writeLine(b, "URL:"+href)
+			}
+			writeLine(b, "END:VEVENT")
+		}
+	}
+
+	writeLine(b, "END:VCALENDAR")
+
+	return b.Flush()
+}
+
+// uri приводит адрес страницы расписания к URI (RFC 3986): кириллица в запросе и пути кодируется.
+// Если адрес не разбирается, возвращается пустая строка
+func uri(href string) string {
+	if href == "" {
+		return ""
+	}
+
+	u, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	u.RawQuery = u.Query().Encode()
+
+	return u.String()
+}
+
+// uid возвращает идентификатор события, не зависящий от кабинета, преподавателя и названия пары
+func uid(name string, lesson model.Lesson) string {
+	sum := sha1.Sum([]byte(strings.Join([]string{
+		name,
+		lesson.Start.UTC().Format(utcLayout),
+		lesson.Group,
+		lesson.Subgroup,
+	}, "|")))
+
+	return hex.EncodeToString(sum[:]) + "@hmtpk.ru"
+}
+
+func description(lesson model.Lesson) string {
+	var lines []string
+	if lesson.Teacher != "" {
+		lines = append(lines, "Преподаватель: "+lesson.Teacher)
+	}
+	if lesson.Group != "" {
+		lines = append(lines, "Группа: "+lesson.Group)
+	}
+	if lesson.Subgroup != "" {
+		lines = append(lines, "Подгруппа: "+lesson.Subgroup)
+	}
+	if lesson.Num != "" {
+		lines = append(lines, "Пара: "+lesson.Num)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func joinNotEmpty(sep string, values ...string) string {
+	var notEmpty []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			notEmpty = append(notEmpty, value)
+		}
+	}
+
+	return strings.Join(notEmpty, sep)
+}
+
+// textEscaper экранирует значение типа TEXT (RFC 5545, 3.3.11)
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+func escape(value string) string {
+	return textEscaper.Replace(value)
+}
+
+// writeLine записывает строку календаря, перенося ее по 75 октетов без разрыва символов UTF-8
+func writeLine(w *bufio.Writer, line string) {
+	for length := maxLineLength; len(line) > length; length = maxLineLength - 1 {
+		cut := length
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		_, _ = fmt.Fprintf(w, "%s\r\n ", line[:cut])
+		line = line[cut:]
+	}
+
+	_, _ = w.WriteString(line + "\r\n")
+}
@@ -0,0 +1,131 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+	"github.com/chazari-x/hmtpk_parser/v2/schedule/group"
+)
+
+func TestICalendar(t *testing.T) {
+	now = func() time.Time { return time.Date(2024, 3, 17, 12, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	page, err := os.Open(filepath.Join("..", "testdata", "group_schedule.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = page.Close()
+	}()
+
+	weeklySchedule, err := group.ParseSchedule(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lessons int
+	for _, day := range weeklySchedule {
+		lessons += len(day.Lessons)
+	}
+
+	var buf bytes.Buffer
+	if err = ICalendar(&buf, "ИС-21", weeklySchedule); err != nil {
+		t.Fatalf("ICalendar() error = %v", err)
+	}
+	calendar := buf.String()
+
+	if !strings.HasSuffix(calendar, "END:VCALENDAR\r\n") {
+		t.Errorf("ICalendar() must end with END:VCALENDAR and CRLF")
+	}
+	if got := strings.Count(calendar, "BEGIN:VEVENT"); got != lessons {
+		t.Errorf("ICalendar() events = %d, want %d", got, lessons)
+	}
+	unfolded := strings.ReplaceAll(calendar, "\r\n ", "")
+	for _, want := range []string{
+		"TZID:Asia/Yekaterinburg",
+		"DTSTART;TZID=Asia/Yekaterinburg:20240318T083000",
+		"DTEND;TZID=Asia/Yekaterinburg:20240318T100000",
+		"DTSTAMP:20240317T120000Z",
+		"SUMMARY:Математика",
+		`DESCRIPTION:Преподаватель: Иванов Иван Иванович\nПара: 1`,
+	} {
+		if !strings.Contains(unfolded, want) {
+			t.Errorf("ICalendar() does not contain %q", want)
+		}
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(calendar, "\r\n"), "\r\n") {
+		if len(line) > maxLineLength {
+			t.Errorf("ICalendar() line is longer than %d octets: %q", maxLineLength, line)
+		}
+	}
+}
+
+func Test_uid(t *testing.T) {
+	start := time.Date(2024, 3, 18, 8, 30, 0, 0, time.UTC)
+	lesson := model.Lesson{Name: "Математика", Room: "205", Teacher: "Иванов Иван Иванович", Start: start}
+
+	moved := lesson
+	moved.Room, moved.Teacher = "312", "Петрова Анна Сергеевна"
+	if uid("ИС-21", lesson) != uid("ИС-21", moved) {
+		t.Errorf("uid() must not depend on room and teacher")
+	}
+
+	subgroup := lesson
+	subgroup.Subgroup = "1"
+	if uid("ИС-21", lesson) == uid("ИС-21", subgroup) {
+		t.Errorf("uid() must differ for subgroups")
+	}
+}
+
+func Test_writeLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "ascii", value: strings.Repeat("a", 200)},
+		{name: "cyrillic", value: strings.Repeat("я", 100)},
+		{name: "escaped", value: escape("Кабинет 205, корпус; 1\nвход\\двор")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := bufio.NewWriter(&buf)
+			writeLine(w, "DESCRIPTION:"+tt.value)
+			_ = w.Flush()
+
+			// Разворачиваем перенесенные строки обратно (RFC 5545, 3.1)
+			unfolded := strings.ReplaceAll(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n ", "")
+			if unfolded != "DESCRIPTION:"+tt.value {
+				t.Errorf("writeLine() unfolded = %q, want %q", unfolded, "DESCRIPTION:"+tt.value)
+			}
+		})
+	}
+}
+
+func TestICalendar_URL(t *testing.T) {
+	start := time.Date(2024, 3, 18, 8, 30, 0, 0, time.UTC)
+	weeklySchedule := []model.Schedule{{
+		Href:    "https://hmtpk.ru/ru/teachers/schedule/?teacher=Иванов Иван Иванович&date_edu1c=18.03.2024&send=Показать#current",
+		Lessons: []model.Lesson{{Name: "Математика", Start: start, End: start.Add(time.Hour * 3 / 2)}},
+	}}
+
+	var buf bytes.Buffer
+	if err := ICalendar(&buf, "Иванов Иван Иванович", weeklySchedule); err != nil {
+		t.Fatalf("ICalendar() error = %v", err)
+	}
+
+	const want = "URL:https://hmtpk.ru/ru/teachers/schedule/?date_edu1c=18.03.2024" +
+		"&send=%D0%9F%D0%BE%D0%BA%D0%B0%D0%B7%D0%B0%D1%82%D1%8C" +
+		"&teacher=%D0%98%D0%B2%D0%B0%D0%BD%D0%BE%D0%B2+%D0%98%D0%B2%D0%B0%D0%BD+%D0%98%D0%B2%D0%B0%D0%BD%D0%BE%D0%B2%D0%B8%D1%87" +
+		"#current"
+	if unfolded := strings.ReplaceAll(buf.String(), "\r\n ", ""); !strings.Contains(unfolded, want+"\r\n") {
+		t.Errorf("ICalendar() does not contain %q:\n%s", want, unfolded)
+	}
+}
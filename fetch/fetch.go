@@ -0,0 +1,228 @@
+package fetch
+
+import (
+	"context"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/chazari-x/hmtpk_parser/v2/backoff"
+	"github.com/chazari-x/hmtpk_parser/v2/breaker"
+	"github.com/chazari-x/hmtpk_parser/v2/errors"
+	"github.com/chazari-x/hmtpk_parser/v2/ratelimit"
+)
+
+// DefaultBaseURL адрес сайта колледжа, используемый по умолчанию
+const DefaultBaseURL = "https://hmtpk.ru"
+
+// Повторы неудачных запросов по умолчанию
+const (
+	DefaultAttempts   = 3
+	DefaultBackoff    = time.Millisecond * 200
+	DefaultMaxBackoff = time.Second * 5
+)
+
+// Client выполняет запросы к сайту hmtpk.ru (или его зеркалу) через общий http.Client
+type Client struct {
+	http       *http.Client
+	baseURL    string
+	limiter    *ratelimit.Limiter
+	breaker    *breaker.Breaker
+	attempts   int
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+// Option настраивает Client при создании
+type Option func(*Client)
+
+// WithLimiter ограничивает частоту запросов к сайту. Ограничение общее для всех,
+// кто использует этот Client: расписаний групп, преподавателей и объявлений
+func WithLimiter(limiter *ratelimit.Limiter) Option {
+	return func(c *Client) {
+		c.limiter = limiter
+	}
+}
+
+// WithBreaker задает размыкатель цепи: пока он разомкнут, Document сразу возвращает errors.ErrorCircuitOpen
+func WithBreaker(b *breaker.Breaker) Option {
+	return func(c *Client) {
+		c.breaker = b
+	}
+}
+
+// WithRetry задает количество попыток запроса, задержку перед второй попыткой и наибольшую задержку.
+// Повторяются запросы, которые не дошли до сайта, и ответы 429 и 5xx. Задержка удваивается
+// после каждой попытки, но не меньше Retry-After из ответа сайта. attempts = 1 отключает повторы
+func WithRetry(attempts int, backoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.attempts, c.backoff, c.maxBackoff = attempts, backoff, maxBackoff
+	}
+}
+
+// NewClient создает клиент для запросов к сайту по адресу baseURL.
+// Если client равен nil, используется http.DefaultClient, если baseURL пустой - DefaultBaseURL
+func NewClient(client *http.Client, baseURL string, opts ...Option) *Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	c := &Client{
+		http:       client,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		attempts:   DefaultAttempts,
+		backoff:    DefaultBackoff,
+		maxBackoff: DefaultMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.attempts < 1 {
+		c.attempts = 1
+	}
+
+	return c
+}
+
+// URL возвращает абсолютный адрес страницы сайта по ее пути
+func (c *Client) URL(path string) string {
+	return c.baseURL + path
+}
+
+// Document получает html страницу по адресу href. Если сайт недоступен или отвечает ошибкой,
+// запрос повторяется (см. WithRetry), а после последней попытки возвращается *errors.UpstreamError.
+// Повторы прекращаются, если следующая попытка не успевает до истечения ctx.
+// Если сайт недоступен и размыкатель цепи разомкнут, сразу возвращается errors.ErrorCircuitOpen
+func (c *Client) Document(ctx context.Context, href string) (*goquery.Document, error) {
+	if err := c.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	doc, err := c.retry(ctx, href)
+
+	var upstream *errors.UpstreamError
+	switch {
+	case err == nil:
+		c.breaker.Success()
+	case !stderrors.As(err, &upstream):
+		c.breaker.Cancel()
+	case retryable(ctx, upstream):
+		c.breaker.Failure()
+	case upstream.StatusCode != 0:
+		// сайт ответил, значит он доступен
+		c.breaker.Success()
+	default:
+		c.breaker.Cancel()
+	}
+
+	return doc, err
+}
+
+// retry выполняет запрос с повторами
+func (c *Client) retry(ctx context.Context, href string) (*goquery.Document, error) {
+	for attempt := 1; ; attempt++ {
+		doc, err := c.document(ctx, href)
+		if err == nil {
+			return doc, nil
+		}
+
+		var upstream *errors.UpstreamError
+		if !stderrors.As(err, &upstream) {
+			return nil, err
+		}
+		upstream.Attempts = attempt
+
+		if attempt >= c.attempts || !retryable(ctx, upstream) {
+			return nil, upstream
+		}
+
+		delay := backoff.Delay(c.backoff, c.maxBackoff, attempt)
+		if upstream.RetryAfter > delay {
+			delay = upstream.RetryAfter
+		}
+
+		if delay > c.maxBackoff {
+			return nil, upstream
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return nil, upstream
+		}
+
+		if err = backoff.Sleep(ctx, delay); err != nil {
+			return nil, upstream
+		}
+	}
+}
+
+// document выполняет одну попытку запроса. Ошибки ответа и сети возвращаются как *errors.UpstreamError
+func (c *Client) document(ctx context.Context, href string) (*goquery.Document, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, "POST", href, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(request)
+	if err != nil {
+		return nil, &errors.UpstreamError{URL: href, Err: err}
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &errors.UpstreamError{
+			StatusCode: resp.StatusCode,
+			URL:        href,
+			RetryAfter: retryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	return goquery.NewDocumentFromReader(resp.Body)
+}
+
+// retryable сообщает, имеет ли смысл повторить запрос
+func retryable(ctx context.Context, err *errors.UpstreamError) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err.StatusCode == 0 {
+		return true
+	}
+
+	return err.StatusCode == http.StatusTooManyRequests || err.StatusCode >= 500
+}
+
+// retryAfter разбирает заголовок Retry-After в секундах или в виде даты
+func retryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,85 @@
+package fetch
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chazari-x/hmtpk_parser/v2/errors"
+)
+
+func TestClient_Document(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		retryAfter   string
+		wantRequests int32
+		wantStatus   int
+		wantRetry    time.Duration
+	}{
+		{name: "ok", statuses: []int{200}, wantRequests: 1},
+		{name: "retry after 5xx", statuses: []int{503, 502, 200}, wantRequests: 3},
+		{name: "attempts exhausted", statuses: []int{500, 500, 500}, wantRequests: 3, wantStatus: 500},
+		{name: "not retried", statuses: []int{404}, wantRequests: 1, wantStatus: 404},
+		{name: "retry after too long", statuses: []int{429}, retryAfter: "120", wantRequests: 1, wantStatus: 429, wantRetry: 2 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&requests, 1)
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.statuses[n-1])
+				_, _ = w.Write([]byte("<html><body>ok</body></html>"))
+			}))
+			defer srv.Close()
+
+			c := NewClient(srv.Client(), srv.URL, WithRetry(3, time.Millisecond, 10*time.Millisecond))
+			doc, err := c.Document(context.Background(), c.URL("/"))
+
+			if requests != tt.wantRequests {
+				t.Errorf("Document() made %d requests, want %d", requests, tt.wantRequests)
+			}
+
+			if tt.wantStatus == 0 {
+				if err != nil || doc.Find("body").Text() != "ok" {
+					t.Fatalf("Document() error = %v", err)
+				}
+				return
+			}
+
+			var upstream *errors.UpstreamError
+			if !stderrors.As(err, &upstream) {
+				t.Fatalf("Document() error = %v, want *errors.UpstreamError", err)
+			}
+			if !stderrors.Is(err, errors.ErrorBadResponse) {
+				t.Errorf("Document() error = %v, want %v", err, errors.ErrorBadResponse)
+			}
+			if upstream.StatusCode != tt.wantStatus || upstream.Attempts != int(tt.wantRequests) || upstream.RetryAfter != tt.wantRetry {
+				t.Errorf("Document() error = %+v, want status %d after %d attempts", upstream, tt.wantStatus, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestClient_DocumentNetworkError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	c := NewClient(srv.Client(), srv.URL, WithRetry(2, time.Millisecond, time.Millisecond))
+	_, err := c.Document(context.Background(), c.URL("/"))
+
+	var upstream *errors.UpstreamError
+	if !stderrors.As(err, &upstream) || upstream.StatusCode != 0 || upstream.Attempts != 2 || upstream.Err == nil {
+		t.Fatalf("Document() error = %#v, want network UpstreamError after 2 attempts", err)
+	}
+	if stderrors.Is(err, errors.ErrorBadResponse) {
+		t.Errorf("Document() network error matches %v", errors.ErrorBadResponse)
+	}
+}
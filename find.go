@@ -0,0 +1,63 @@
+package hmtpk_parser
+
+import (
+	"context"
+
+	"github.com/chazari-x/hmtpk_parser/v2/lookup"
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+)
+
+// FindGroups ищет группы по названию с учетом опечаток и похожих латинских букв,
+// например "ис21" или "ИC-21" с латинской C
+func (c *Controller) FindGroups(ctx context.Context, query string) ([]lookup.Candidate, error) {
+	options, err := c.GetGroupOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return lookup.Find(options, query), nil
+}
+
+// FindTeachers ищет преподавателей по ФИО с учетом опечаток, например "иванов и.и."
+func (c *Controller) FindTeachers(ctx context.Context, query string) ([]lookup.Candidate, error) {
+	options, err := c.GetTeacherOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return lookup.Find(options, query), nil
+}
+
+// GetScheduleByGroupName по названию группы и дате получает расписание на неделю.
+// Если группа не найдена, возвращается errors.ErrorNotFound, если нашлись только группы с опечаткой -
+// *errors.NotFoundError с ними, если подходит несколько групп - errors.ErrorAmbiguous
+func (c *Controller) GetScheduleByGroupName(ctx context.Context, name, date string) ([]model.Schedule, error) {
+	options, err := c.GetGroupOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := lookup.Best(options, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.GetScheduleByGroup(ctx, group.Value, date)
+}
+
+// GetScheduleByTeacherName по ФИО преподавателя в свободной форме и дате получает расписание на неделю.
+// Если преподаватель не найден, возвращается errors.ErrorNotFound, если нашлись только преподаватели с опечаткой -
+// *errors.NotFoundError с ними, если подходит несколько - errors.ErrorAmbiguous
+func (c *Controller) GetScheduleByTeacherName(ctx context.Context, name, date string) ([]model.Schedule, error) {
+	options, err := c.GetTeacherOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	teacher, err := lookup.Best(options, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.GetScheduleByTeacher(ctx, teacher.Value, date)
+}
@@ -0,0 +1,123 @@
+package hmtpk_parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "перезаписать эталонные файлы testdata/golden")
+
+// TestGolden прогоняет парсеры по страницам из testdata и сравнивает результат с эталоном.
+// Все страницы составлены вручную по селекторам парсеров, а не сохранены с сайта, поэтому
+// особенности настоящей разметки не проверяются. После осознанного изменения разметки
+// эталоны обновляются командой go test -run TestGolden -update
+func TestGolden(t *testing.T) {
+	tests := []struct {
+		name  string
+		page  string
+		parse func(r io.Reader) (any, error)
+	}{
+		{
+			name:  "group_schedule",
+			page:  "group_schedule.html",
+			parse: func(r io.Reader) (any, error) { return ParseGroupSchedule(r) },
+		},
+		{
+			name:  "group_options",
+			page:  "group_schedule.html",
+			parse: func(r io.Reader) (any, error) { return ParseGroupOptions(r) },
+		},
+		{
+			name:  "teacher_schedule",
+			page:  "teacher_schedule.html",
+			parse: func(r io.Reader) (any, error) { return ParseTeacherSchedule(r) },
+		},
+		{
+			name:  "teacher_options",
+			page:  "teacher_schedule.html",
+			parse: func(r io.Reader) (any, error) { return ParseTeacherOptions(r) },
+		},
+		{
+			name:  "announce_page",
+			page:  "announce_page.html",
+			parse: func(r io.Reader) (any, error) { return ParseAnnouncePage(r) },
+		},
+		{
+			name:  "announce_last_page",
+			page:  "announce_last_page.html",
+			parse: func(r io.Reader) (any, error) { return ParseAnnouncePage(r) },
+		},
+		{
+			name:  "announce_detail",
+			page:  "announce_detail.html",
+			parse: func(r io.Reader) (any, error) { return ParseAnnounce(r) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := os.Open(filepath.Join("testdata", tt.page))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() {
+				_ = page.Close()
+			}()
+
+			parsed, err := tt.parse(page)
+			if err != nil {
+				t.Fatalf("parse() error = %v", err)
+			}
+
+			var buf bytes.Buffer
+			encoder := json.NewEncoder(&buf)
+			encoder.SetEscapeHTML(false)
+			encoder.SetIndent("", "  ")
+			if err = encoder.Encode(parsed); err != nil {
+				t.Fatal(err)
+			}
+			got := buf.Bytes()
+
+			golden := filepath.Join("testdata", "golden", tt.name+".json")
+			if *update {
+				if err = os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s differs from golden file %s:\n%s", tt.page, golden, diffLines(string(want), string(got)))
+			}
+		})
+	}
+}
+
+// diffLines возвращает первую отличающуюся строку эталона и результата с номером строки
+func diffLines(want, got string) string {
+	wantLines, gotLines := strings.Split(want, "\n"), strings.Split(got, "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return fmt.Sprintf("line %d:\n- %s\n+ %s", i+1, w, g)
+		}
+	}
+
+	return ""
+}
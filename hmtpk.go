@@ -2,37 +2,84 @@ package hmtpk_parser
 
 import (
 	"context"
+	"time"
 
 	"github.com/chazari-x/hmtpk_parser/v2/announce"
+	"github.com/chazari-x/hmtpk_parser/v2/breaker"
 	"github.com/chazari-x/hmtpk_parser/v2/errors"
+	"github.com/chazari-x/hmtpk_parser/v2/fetch"
+	"github.com/chazari-x/hmtpk_parser/v2/ratelimit"
 	"github.com/chazari-x/hmtpk_parser/v2/schedule"
 	"github.com/chazari-x/hmtpk_parser/v2/schedule/group"
 	"github.com/chazari-x/hmtpk_parser/v2/schedule/teacher"
 	"github.com/chazari-x/hmtpk_parser/v2/storage"
-	"github.com/go-redis/redis/v8"
+	"github.com/chazari-x/hmtpk_parser/v2/utils"
 	"github.com/sirupsen/logrus"
 
 	"github.com/chazari-x/hmtpk_parser/v2/model"
 )
 
 type Controller struct {
-	r        *storage.Redis
-	log      *logrus.Logger
-	group    *group.Controller
-	teacher  *teacher.Controller
-	announce *announce.Announce
+	cache       storage.Cache
+	log         *logrus.Logger
+	concurrency int
+	breaker     *breaker.Breaker
+	group       *group.Controller
+	teacher     *teacher.Controller
+	announce    *announce.Announce
 }
 
-func NewController(client *redis.Client, logger *logrus.Logger) *Controller {
+// NewController создает контроллер, кэширующий данные в cache.
+// Если cache равен nil, данные всегда запрашиваются с сайта
+func NewController(cache storage.Cache, logger *logrus.Logger, opts ...Option) *Controller {
+	cfg := config{
+		concurrency: DefaultConcurrency,
+		attempts:    fetch.DefaultAttempts,
+		backoff:     fetch.DefaultBackoff,
+		maxBackoff:  fetch.DefaultMaxBackoff,
+		threshold:   breaker.DefaultThreshold,
+		cooldown:    breaker.DefaultCooldown,
+		cachePolicy: DefaultCachePolicy(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if cfg.concurrency < 1 {
+		cfg.concurrency = 1
+	}
+
+	b := breaker.New(cfg.threshold, cfg.cooldown)
+	client := fetch.NewClient(cfg.client, cfg.baseURL,
+		fetch.WithLimiter(ratelimit.New(cfg.rateLimit, cfg.burst)),
+		fetch.WithRetry(cfg.attempts, cfg.backoff, cfg.maxBackoff),
+		fetch.WithBreaker(b),
+	)
+
+	schedulePolicy := schedule.CachePolicy{
+		CurrentWeek: cfg.cachePolicy.CurrentWeek,
+		PastWeeks:   cfg.cachePolicy.PastWeeks,
+		FutureWeeks: cfg.cachePolicy.FutureWeeks,
+		Options:     cfg.cachePolicy.Options,
+	}
+
 	return &Controller{
-		r:        &storage.Redis{Redis: client},
-		log:      logger,
-		group:    group.NewController(client, logger),
-		teacher:  teacher.NewController(client, logger),
-		announce: announce.NewAnnounce(logger),
+		cache:       cache,
+		log:         logger,
+		concurrency: cfg.concurrency,
+		breaker:     b,
+		group:       group.NewController(cache, schedulePolicy, client, logger),
+		teacher:     teacher.NewController(cache, schedulePolicy, client, logger),
+		announce:    announce.NewAnnounce(cache, cfg.cachePolicy.Announces, client, logger),
 	}
 }
 
+// CircuitState возвращает состояние размыкателя цепи запросов к сайту. Пока он разомкнут (breaker.Open),
+// запросы не в кэше завершаются ошибкой errors.ErrorCircuitOpen без обращения к сайту
+func (c *Controller) CircuitState() breaker.State {
+	return c.breaker.State()
+}
+
 // GetScheduleByGroup по идентификатору группы и дате получает расписание на неделю
 func (c *Controller) GetScheduleByGroup(ctx context.Context, group, date string) ([]model.Schedule, error) {
 	return c.getSchedule(ctx, group, date, c.group)
@@ -43,16 +90,49 @@ func (c *Controller) GetScheduleByTeacher(ctx context.Context, teacher, date str
 	return c.getSchedule(ctx, teacher, date, c.teacher)
 }
 
-// GetGroupOptions получает список групп
+// GetScheduleByGroupDate по идентификатору группы получает расписание на неделю, в которую входит date
+func (c *Controller) GetScheduleByGroupDate(ctx context.Context, group string, date time.Time) ([]model.Schedule, error) {
+	return c.getSchedule(ctx, group, utils.FormatDate(date), c.group)
+}
+
+// GetScheduleByTeacherDate по ФИО преподавателя получает расписание на неделю, в которую входит date
+func (c *Controller) GetScheduleByTeacherDate(ctx context.Context, teacher string, date time.Time) ([]model.Schedule, error) {
+	return c.getSchedule(ctx, teacher, utils.FormatDate(date), c.teacher)
+}
+
+// GetSchedule получает расписание группы или преподавателя на неделю, в которую входит date
+func (c *Controller) GetSchedule(ctx context.Context, kind schedule.Kind, subject string, date time.Time) ([]model.Schedule, error) {
+	adapter, err := c.adapter(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.getSchedule(ctx, subject, utils.FormatDate(date), adapter)
+}
+
+// GetGroupOptions получает список групп. Признака устаревших данных у списка нет:
+// пока сайт недоступен, из кэша может вернуться устаревший список
 func (c *Controller) GetGroupOptions(ctx context.Context) ([]model.Option, error) {
 	return c.group.GetOptions(ctx)
 }
 
-// GetTeacherOptions получает список преподавателей
+// GetTeacherOptions получает список преподавателей. Признака устаревших данных у списка нет:
+// пока сайт недоступен, из кэша может вернуться устаревший список
 func (c *Controller) GetTeacherOptions(ctx context.Context) ([]model.Option, error) {
 	return c.teacher.GetOptions(ctx)
 }
 
+func (c *Controller) adapter(kind schedule.Kind) (schedule.Adapter, error) {
+	switch kind {
+	case schedule.Group:
+		return c.group, nil
+	case schedule.Teacher:
+		return c.teacher, nil
+	default:
+		return nil, errors.ErrorBadRequest
+	}
+}
+
 func (c *Controller) getSchedule(ctx context.Context, name, date string, adapter schedule.Adapter) ([]model.Schedule, error) {
 	if name == "0" || name == "" {
 		return nil, errors.ErrorBadRequest
@@ -69,3 +149,9 @@ func (c *Controller) GetAnnounces(ctx context.Context, page int) (model.Announce
 
 	return c.announce.GetAnnounces(ctx, page)
 }
+
+// GetAnnounce получает объявление целиком по пути на сайте из model.Announce.Path.
+// Если объявление взято из кэша и может быть устаревшим, Stale равен true
+func (c *Controller) GetAnnounce(ctx context.Context, path string) (model.Announce, error) {
+	return c.announce.GetAnnounce(ctx, path)
+}
@@ -2,19 +2,60 @@ package hmtpk_parser
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/chazari-x/hmtpk_parser/v2/announce"
+	hmtpkErrors "github.com/chazari-x/hmtpk_parser/v2/errors"
+	"github.com/chazari-x/hmtpk_parser/v2/fetch"
 	"github.com/chazari-x/hmtpk_parser/v2/model"
+	"github.com/chazari-x/hmtpk_parser/v2/schedule"
 	"github.com/chazari-x/hmtpk_parser/v2/schedule/group"
 	"github.com/chazari-x/hmtpk_parser/v2/schedule/teacher"
 	"github.com/chazari-x/hmtpk_parser/v2/storage"
+	"github.com/chazari-x/hmtpk_parser/v2/swr"
 	"github.com/chazari-x/hmtpk_parser/v2/utils"
 	"github.com/sirupsen/logrus"
 )
 
+// newTestServer поднимает локальную замену hmtpk.ru, отдающую сохраненные страницы из testdata
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var page string
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/ru/students/schedule"):
+			page = "group_schedule.html"
+		case strings.HasPrefix(r.URL.Path, "/ru/teachers/schedule"):
+			page = "teacher_schedule.html"
+		case strings.HasPrefix(r.URL.Path, "/ru/press-center/announce/"):
+			page = "announce_detail.html"
+		case strings.HasPrefix(r.URL.Path, "/ru/press-center/announce"):
+			page = "announce_page.html"
+			if r.URL.Query().Get("PAGEN_1") == "70" {
+				page = "announce_last_page.html"
+			}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+
+		http.ServeFile(w, r, filepath.Join("testdata", page))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
 func Test_getDate(t *testing.T) {
 	tests := []struct {
 		name string
@@ -46,7 +87,104 @@ func Test_getDate(t *testing.T) {
 	}
 }
 
+func Test_parseDate(t *testing.T) {
+	tests := []struct {
+		name    string
+		title   string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name:  "date first",
+			title: "20 марта 2024, Среда",
+			want:  time.Date(2024, 3, 20, 0, 0, 0, 0, utils.Location),
+		},
+		{
+			name:  "weekday first",
+			title: "Понедельник, 20 мая 2024",
+			want:  time.Date(2024, 5, 20, 0, 0, 0, 0, utils.Location),
+		},
+		{
+			name:    "empty",
+			title:   "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := utils.ParseDate(tt.title)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseDate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseDate() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseLessonTime(t *testing.T) {
+	day := time.Date(2024, 3, 20, 0, 0, 0, 0, utils.Location)
+
+	tests := []struct {
+		name      string
+		time      string
+		wantStart time.Time
+		wantEnd   time.Time
+		wantErr   bool
+	}{
+		{
+			name:      "hyphen",
+			time:      "08:30-10:00",
+			wantStart: day.Add(8*time.Hour + 30*time.Minute),
+			wantEnd:   day.Add(10 * time.Hour),
+		},
+		{
+			name:      "dash with spaces",
+			time:      " 13:50 – 15:20 ",
+			wantStart: day.Add(13*time.Hour + 50*time.Minute),
+			wantEnd:   day.Add(15*time.Hour + 20*time.Minute),
+		},
+		{
+			name:    "empty",
+			time:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStart, gotEnd, err := utils.ParseLessonTime(day, tt.time)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseLessonTime() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !gotStart.Equal(tt.wantStart) || !gotEnd.Equal(tt.wantEnd) {
+				t.Errorf("ParseLessonTime() got = %v - %v, want %v - %v", gotStart, gotEnd, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestController_GetScheduleByGroupDate(t *testing.T) {
+	srv := newTestServer(t)
+
+	c := NewController(nil, logrus.StandardLogger(), WithBaseURL(srv.URL), WithHTTPClient(srv.Client()))
+	got, err := c.GetScheduleByGroupDate(context.Background(), "114808", time.Date(2024, 3, 20, 12, 0, 0, 0, utils.Location))
+	if err != nil {
+		t.Fatalf("GetScheduleByGroupDate() error = %v", err)
+	}
+
+	for i, day := range got {
+		if want := time.Date(2024, 3, 18+i, 0, 0, 0, 0, utils.Location); !day.Date.Equal(want) || day.Weekday != want.Weekday() {
+			t.Errorf("GetScheduleByGroupDate() day %d = %v (%v), want %v", i, day.Date, day.Weekday, want)
+		}
+	}
+}
+
 func TestController_GetScheduleByGroup(t *testing.T) {
+	srv := newTestServer(t)
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 	defer cancel()
 
@@ -57,16 +195,16 @@ func TestController_GetScheduleByGroup(t *testing.T) {
 	}
 	tests := []struct {
 		name    string
-		r       *storage.Redis
+		cache   storage.Cache
 		log     *logrus.Logger
 		args    args
 		noWant  []model.Schedule
 		wantErr bool
 	}{
 		{
-			name: "",
-			r:    nil,
-			log:  logrus.StandardLogger(),
+			name:  "",
+			cache: nil,
+			log:   logrus.StandardLogger(),
 			args: args{
 				group: "114808",
 				date:  "22.02.2024",
@@ -79,8 +217,8 @@ func TestController_GetScheduleByGroup(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := NewController(nil, tt.log)
-			got, err := c.GetScheduleByGroup(tt.args.group, tt.args.date, tt.args.ctx)
+			c := NewController(nil, tt.log, WithBaseURL(srv.URL), WithHTTPClient(srv.Client()))
+			got, err := c.GetScheduleByGroup(tt.args.ctx, tt.args.group, tt.args.date)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetScheduleByGroup() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -95,6 +233,8 @@ func TestController_GetScheduleByGroup(t *testing.T) {
 }
 
 func TestController_GetScheduleByTeacher(t *testing.T) {
+	srv := newTestServer(t)
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 	defer cancel()
 
@@ -105,16 +245,16 @@ func TestController_GetScheduleByTeacher(t *testing.T) {
 	}
 	tests := []struct {
 		name    string
-		r       *storage.Redis
+		cache   storage.Cache
 		log     *logrus.Logger
 		args    args
 		noWant  []model.Schedule
 		wantErr bool
 	}{
 		{
-			name: "",
-			r:    nil,
-			log:  logrus.StandardLogger(),
+			name:  "",
+			cache: nil,
+			log:   logrus.StandardLogger(),
 			args: args{
 				teacher: "<>",
 				date:    "21.02.2024",
@@ -126,8 +266,8 @@ func TestController_GetScheduleByTeacher(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := NewController(nil, tt.log)
-			got, err := c.GetScheduleByTeacher(tt.args.teacher, tt.args.date, tt.args.ctx)
+			c := NewController(nil, tt.log, WithBaseURL(srv.URL), WithHTTPClient(srv.Client()))
+			got, err := c.GetScheduleByTeacher(tt.args.ctx, tt.args.teacher, tt.args.date)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetScheduleByTeacher() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -143,11 +283,13 @@ func TestController_GetScheduleByTeacher(t *testing.T) {
 }
 
 func TestController_GetGroupValues(t *testing.T) {
+	srv := newTestServer(t)
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 	defer cancel()
 
 	type fields struct {
-		r       *storage.Redis
+		cache   storage.Cache
 		log     *logrus.Logger
 		group   *group.Controller
 		teacher *teacher.Controller
@@ -164,10 +306,10 @@ func TestController_GetGroupValues(t *testing.T) {
 		{
 			name: "",
 			fields: fields{
-				r:       nil,
+				cache:   nil,
 				log:     logrus.StandardLogger(),
-				group:   group.NewController(nil, logrus.StandardLogger()),
-				teacher: teacher.NewController(nil, logrus.StandardLogger()),
+				group:   group.NewController(nil, schedule.CachePolicy{}, fetch.NewClient(srv.Client(), srv.URL), logrus.StandardLogger()),
+				teacher: teacher.NewController(nil, schedule.CachePolicy{}, fetch.NewClient(srv.Client(), srv.URL), logrus.StandardLogger()),
 			},
 			args: args{
 				ctx: ctx,
@@ -178,7 +320,7 @@ func TestController_GetGroupValues(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := &Controller{
-				r:       tt.fields.r,
+				cache:   tt.fields.cache,
 				log:     tt.fields.log,
 				group:   tt.fields.group,
 				teacher: tt.fields.teacher,
@@ -198,11 +340,13 @@ func TestController_GetGroupValues(t *testing.T) {
 }
 
 func TestController_GetTeacherValues(t *testing.T) {
+	srv := newTestServer(t)
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 	defer cancel()
 
 	type fields struct {
-		r       *storage.Redis
+		cache   storage.Cache
 		log     *logrus.Logger
 		group   *group.Controller
 		teacher *teacher.Controller
@@ -219,10 +363,10 @@ func TestController_GetTeacherValues(t *testing.T) {
 		{
 			name: "",
 			fields: fields{
-				r:       nil,
+				cache:   nil,
 				log:     logrus.StandardLogger(),
-				group:   group.NewController(nil, logrus.StandardLogger()),
-				teacher: teacher.NewController(nil, logrus.StandardLogger()),
+				group:   group.NewController(nil, schedule.CachePolicy{}, fetch.NewClient(srv.Client(), srv.URL), logrus.StandardLogger()),
+				teacher: teacher.NewController(nil, schedule.CachePolicy{}, fetch.NewClient(srv.Client(), srv.URL), logrus.StandardLogger()),
 			},
 			args: args{
 				ctx: ctx,
@@ -233,7 +377,7 @@ func TestController_GetTeacherValues(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := &Controller{
-				r:       tt.fields.r,
+				cache:   tt.fields.cache,
 				log:     tt.fields.log,
 				group:   tt.fields.group,
 				teacher: tt.fields.teacher,
@@ -253,8 +397,10 @@ func TestController_GetTeacherValues(t *testing.T) {
 }
 
 func TestController_GetAnnounces(t *testing.T) {
+	srv := newTestServer(t)
+
 	log := logrus.StandardLogger()
-	a := announce.NewAnnounce(log)
+	a := announce.NewAnnounce(nil, swr.Policy{}, fetch.NewClient(srv.Client(), srv.URL), log)
 
 	tests := []struct {
 		name    string
@@ -300,3 +446,170 @@ func TestController_GetAnnounces(t *testing.T) {
 		cancel()
 	}
 }
+
+func TestController_Coalescing(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(20 * time.Millisecond)
+		http.ServeFile(w, r, filepath.Join("testdata", "group_schedule.html"))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewController(nil, logrus.StandardLogger(), WithBaseURL(srv.URL), WithHTTPClient(srv.Client()))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.GetScheduleByGroup(context.Background(), "114808", "20.03.2024"); err != nil {
+				t.Errorf("GetScheduleByGroup() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if requests != 1 {
+		t.Errorf("GetScheduleByGroup() made %d requests, want 1", requests)
+	}
+}
+
+func TestController_WithRateLimit(t *testing.T) {
+	srv := newTestServer(t)
+	c := NewController(nil, logrus.StandardLogger(), WithBaseURL(srv.URL), WithHTTPClient(srv.Client()), WithRateLimit(20, 1))
+
+	start := time.Now()
+	for _, date := range []string{"04.03.2024", "11.03.2024", "18.03.2024"} {
+		if _, err := c.GetScheduleByGroup(context.Background(), "114808", date); err != nil {
+			t.Fatalf("GetScheduleByGroup() error = %v", err)
+		}
+	}
+
+	// первый запрос проходит сразу, следующие два ждут по 50ms
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("3 requests took %v, want at least 90ms", elapsed)
+	}
+}
+
+func TestController_WithCachePolicy(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.ServeFile(w, r, filepath.Join("testdata", "group_schedule.html"))
+	}))
+	t.Cleanup(srv.Close)
+
+	policy := DefaultCachePolicy()
+	policy.Options = swr.Policy{}
+
+	c := NewController(storage.NewMemory(0), logrus.StandardLogger(), WithBaseURL(srv.URL), WithHTTPClient(srv.Client()), WithCachePolicy(policy))
+
+	tests := []struct {
+		name         string
+		get          func() error
+		wantRequests int32
+	}{
+		{
+			name: "options not cached",
+			get: func() error {
+				_, err := c.GetGroupOptions(context.Background())
+				return err
+			},
+			wantRequests: 2,
+		},
+		{
+			name: "past week cached",
+			get: func() error {
+				_, err := c.GetScheduleByGroup(context.Background(), "114808", "20.03.2024")
+				return err
+			},
+			wantRequests: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&requests, 0)
+			for i := 0; i < 2; i++ {
+				if err := tt.get(); err != nil {
+					t.Fatalf("error = %v", err)
+				}
+			}
+
+			if requests != tt.wantRequests {
+				t.Errorf("made %d requests, want %d", requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestController_AnnounceCache(t *testing.T) {
+	var requests int32
+	srv := newTestServer(t)
+	counting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		srv.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(counting.Close)
+
+	c := NewController(storage.NewMemory(0), logrus.StandardLogger(), WithBaseURL(counting.URL), WithHTTPClient(counting.Client()))
+
+	const path = "/ru/press-center/announce/2024/03/18/den-otkrytykh-dverey/"
+
+	tests := []struct {
+		name  string
+		get   func() (any, error)
+		check func(any) bool
+	}{
+		{
+			name: "page",
+			get:  func() (any, error) { return c.GetAnnounces(context.Background(), 1) },
+			check: func(v any) bool {
+				announces := v.(model.Announces)
+				return len(announces.Announces) != 0 && !announces.Stale
+			},
+		},
+		{
+			name: "detail",
+			get:  func() (any, error) { return c.GetAnnounce(context.Background(), path) },
+			check: func(v any) bool {
+				announce := v.(model.Announce)
+				return announce.Path == path && announce.Title == "День открытых дверей" && strings.Contains(announce.Body, "23 марта") && !announce.Stale
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&requests, 0)
+			for i := 0; i < 2; i++ {
+				got, err := tt.get()
+				if err != nil {
+					t.Fatalf("error = %v", err)
+				}
+				if !tt.check(got) {
+					t.Errorf("got = %+v", got)
+				}
+			}
+
+			// второй вызов получает данные из кэша
+			if requests != 1 {
+				t.Errorf("made %d requests, want 1", requests)
+			}
+		})
+	}
+
+	if _, err := c.GetAnnounce(context.Background(), "https://example.com/"); !errors.Is(err, hmtpkErrors.ErrorBadRequest) {
+		t.Errorf("GetAnnounce() error = %v, want %v", err, hmtpkErrors.ErrorBadRequest)
+	}
+
+	// объявление из кэша без свежего срока отдается с признаком Stale
+	policy := DefaultCachePolicy()
+	policy.Announces = swr.Policy{Stale: time.Hour}
+	stale := NewController(storage.NewMemory(0), logrus.StandardLogger(), WithBaseURL(counting.URL), WithHTTPClient(counting.Client()), WithCachePolicy(policy))
+	for i, want := range []bool{false, true} {
+		announce, err := stale.GetAnnounce(context.Background(), path)
+		if err != nil || announce.Stale != want {
+			t.Errorf("GetAnnounce() call %d stale = %v, error = %v, want stale %v", i+1, announce.Stale, err, want)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package lookup
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/chazari-x/hmtpk_parser/v2/errors"
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+)
+
+// Candidate вариант из списка групп или преподавателей с оценкой совпадения от 0 до 1
+type Candidate struct {
+	model.Option
+	Score float64 `json:"score"`
+}
+
+// Оценки совпадения разных видов. Опечатки оцениваются ниже любого точного совпадения
+const (
+	scoreExact    = 1
+	scoreInitials = 0.95
+	scorePrefix   = 0.6
+	scoreWord     = 0.5
+	scoreTypo     = 0.45
+)
+
+// lookalikes латинские буквы, похожие на кириллические, и соответствующие им кириллические
+var lookalikes = map[rune]rune{
+	'A': 'а', 'a': 'а', 'B': 'в', 'C': 'с', 'c': 'с', 'E': 'е', 'e': 'е', 'H': 'н', 'K': 'к', 'k': 'к',
+	'M': 'м', 'O': 'о', 'o': 'о', 'P': 'р', 'p': 'р', 'T': 'т', 'X': 'х', 'x': 'х', 'Y': 'у', 'y': 'у',
+	'ё': 'е', 'Ё': 'е',
+}
+
+// Normalize приводит строку к виду для сравнения: нижний регистр, "ё" заменена на "е",
+// похожие латинские буквы заменены кириллическими, знаки препинания заменены пробелами
+func Normalize(value string) string {
+	return strings.Join(strings.FieldsFunc(strings.Map(func(r rune) rune {
+		if lookalike, ok := lookalikes[r]; ok {
+			return lookalike
+		}
+
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+
+		return ' '
+	}, value), unicode.IsSpace), " ")
+}
+
+// Find ищет в options варианты, похожие на query, и возвращает их в порядке убывания оценки.
+// Запрос сравнивается с названием и идентификатором без учета регистра, знаков препинания
+// и похожих латинских букв, с фамилией и инициалами ("иванов и.и.") и с опечатками
+func Find(options []model.Option, query string) []Candidate {
+	q := compact(Normalize(query))
+	if q == "" {
+		return nil
+	}
+
+	var candidates []Candidate
+	for _, option := range options {
+		if score := match(q, option); score > 0 {
+			candidates = append(candidates, Candidate{Option: option, Score: score})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+
+		return candidates[i].Label < candidates[j].Label
+	})
+
+	return candidates
+}
+
+// Best возвращает единственный наиболее похожий вариант. Выбираются только точные совпадения,
+// совпадения с инициалами и по началу названия или слова. Если ничего не найдено, возвращается
+// errors.ErrorNotFound, если нашлись только варианты с опечатками - *errors.NotFoundError с ними,
+// если несколько вариантов подходят одинаково - errors.ErrorAmbiguous
+func Best(options []model.Option, query string) (model.Option, error) {
+	candidates := Find(options, query)
+	if len(candidates) == 0 {
+		return model.Option{}, errors.ErrorNotFound
+	}
+
+	if candidates[0].Score <= scoreTypo {
+		suggestions := make([]model.Option, 0, len(candidates))
+		for _, candidate := range candidates {
+			suggestions = append(suggestions, candidate.Option)
+		}
+
+		return model.Option{}, &errors.NotFoundError{Query: query, Suggestions: suggestions}
+	}
+
+	if len(candidates) > 1 && candidates[0].Score == candidates[1].Score {
+		return model.Option{}, errors.ErrorAmbiguous
+	}
+
+	return candidates[0].Option, nil
+}
+
+func match(q string, option model.Option) float64 {
+	words := strings.Fields(Normalize(option.Label))
+	label := strings.Join(words, "")
+	if label == "" {
+		return 0
+	}
+
+	if q == label || q == compact(Normalize(option.Value)) {
+		return scoreExact
+	}
+
+	initials := initials(words)
+	if len(words) > 1 && q == initials {
+		return scoreInitials
+	}
+
+	if strings.HasPrefix(label, q) {
+		return scorePrefix + (scoreInitials-scorePrefix)*ratio(q, label)
+	}
+
+	for _, word := range words[1:] {
+		if strings.HasPrefix(word, q) {
+			return scoreWord
+		}
+	}
+
+	// Опечатки: сравниваем с названием целиком, с его началом той же длины, с отдельными словами
+	// и с фамилией с инициалами
+	length := len([]rune(q))
+	allowed := length / 4
+	if allowed < 1 {
+		allowed = 1
+	}
+
+	best := distance(q, label)
+	if d := distance(q, truncate(label, length)) + 1; d < best {
+		best = d
+	}
+	if len(words) > 1 {
+		for _, word := range append(words, initials) {
+			if d := distance(q, word); d < best {
+				best = d
+			}
+		}
+	}
+
+	if best > allowed {
+		return 0
+	}
+
+	return scoreTypo * (1 - float64(best)/float64(length+1))
+}
+
+func compact(normalized string) string {
+	return strings.ReplaceAll(normalized, " ", "")
+}
+
+// initials возвращает первое слово и первые буквы остальных слов: "иванов иван иванович" - "ивановии"
+func initials(words []string) string {
+	var b strings.Builder
+	for i, word := range words {
+		if i == 0 {
+			b.WriteString(word)
+			continue
+		}
+
+		b.WriteRune([]rune(word)[0])
+	}
+
+	return b.String()
+}
+
+func ratio(q, label string) float64 {
+	return float64(len([]rune(q))) / float64(len([]rune(label)))
+}
+
+func truncate(value string, length int) string {
+	runes := []rune(value)
+	if len(runes) > length {
+		runes = runes[:length]
+	}
+
+	return string(runes)
+}
+
+// distance возвращает расстояние Дамерау-Левенштейна (с ограничением на перестановки)
+// между строками: количество вставок, удалений, замен и перестановок соседних букв
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	rows := make([][]int, len(ra)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(rb)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			rows[i][j] = minimum(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				rows[i][j] = minimum(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return rows[len(ra)][len(rb)]
+}
+
+func minimum(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+
+	return result
+}
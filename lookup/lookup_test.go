@@ -0,0 +1,67 @@
+package lookup
+
+import (
+	"errors"
+	"testing"
+
+	hmtpkErrors "github.com/chazari-x/hmtpk_parser/v2/errors"
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+)
+
+var groups = []model.Option{
+	{Label: "ИС-21", Value: "114808"},
+	{Label: "ИС-22", Value: "114809"},
+	{Label: "ПК-31", Value: "114810"},
+	{Label: "ДО-11", Value: "114811"},
+}
+
+var teachers = []model.Option{
+	{Label: "Иванов Иван Иванович", Value: "Иванов Иван Иванович"},
+	{Label: "Иванова Ирина Петровна", Value: "Иванова Ирина Петровна"},
+	{Label: "Петрова Анна Сергеевна", Value: "Петрова Анна Сергеевна"},
+	{Label: "Семёнов Олег Викторович", Value: "Семёнов Олег Викторович"},
+}
+
+func TestBest(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []model.Option
+		query   string
+		want    string
+		wantErr error
+		suggest string
+	}{
+		{name: "exact", options: groups, query: "ИС-21", want: "114808"},
+		{name: "case and punctuation", options: groups, query: "ис 21", want: "114808"},
+		{name: "latin lookalikes", options: groups, query: "ИC-21", want: "114808"},
+		{name: "latin I as typo", options: groups, query: "IC-21", wantErr: hmtpkErrors.ErrorNotFound, suggest: "ИС-21"},
+		{name: "value", options: groups, query: "114810", want: "114810"},
+		{name: "typo", options: groups, query: "ПК-13", wantErr: hmtpkErrors.ErrorNotFound, suggest: "ПК-31"},
+		{name: "other group number", options: groups, query: "ПК-32", wantErr: hmtpkErrors.ErrorNotFound, suggest: "ПК-31"},
+		{name: "other course", options: groups, query: "ПК-41", wantErr: hmtpkErrors.ErrorNotFound, suggest: "ПК-31"},
+		{name: "other group in single group list", options: groups, query: "ДО-12", wantErr: hmtpkErrors.ErrorNotFound, suggest: "ДО-11"},
+		{name: "ambiguous prefix", options: groups, query: "ис", wantErr: hmtpkErrors.ErrorAmbiguous},
+		{name: "not found", options: groups, query: "ЭК-45", wantErr: hmtpkErrors.ErrorNotFound},
+		{name: "initials", options: teachers, query: "иванов и.и.", want: "Иванов Иван Иванович"},
+		{name: "surname prefers shorter label", options: teachers, query: "иванов", want: "Иванов Иван Иванович"},
+		{name: "yo", options: teachers, query: "семенов", want: "Семёнов Олег Викторович"},
+		{name: "transposition", options: teachers, query: "петорва", wantErr: hmtpkErrors.ErrorNotFound, suggest: "Петрова Анна Сергеевна"},
+		{name: "first name", options: teachers, query: "анна", want: "Петрова Анна Сергеевна"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Best(tt.options, tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Best() error = %v, wantErr %v, candidates %v", err, tt.wantErr, Find(tt.options, tt.query))
+			}
+			if got.Value != tt.want {
+				t.Errorf("Best() got = %v, want %v, candidates %v", got.Value, tt.want, Find(tt.options, tt.query))
+			}
+
+			var notFound *hmtpkErrors.NotFoundError
+			if errors.As(err, &notFound) != (tt.suggest != "") || tt.suggest != "" && notFound.Suggestions[0].Label != tt.suggest {
+				t.Errorf("Best() error = %v, want suggestion %q", err, tt.suggest)
+			}
+		})
+	}
+}
@@ -1,9 +1,20 @@
 package model
 
+import (
+	"strings"
+	"time"
+)
+
 type Schedule struct {
-	Date    string   `json:"date"`
+	// Date день расписания в часовом поясе колледжа
+	Date    time.Time    `json:"date"`
+	Weekday time.Weekday `json:"weekday"`
+	// Title заголовок дня в том виде, в котором он указан на сайте
+	Title   string   `json:"title"`
 	Lessons []Lesson `json:"lesson"`
 	Href    string   `json:"href"`
+	// Stale данные взяты из кэша и могут быть устаревшими, новые запрашиваются в фоне
+	Stale bool `json:"stale,omitempty"`
 }
 
 type Lesson struct {
@@ -15,6 +26,29 @@ type Lesson struct {
 	Group    string `json:"group"`
 	Subgroup string `json:"subgroup"`
 	Teacher  string `json:"teacher"`
+	// Pair номер пары, 0 если номер не указан
+	Pair int `json:"pair"`
+	// Start и End начало и конец пары в день занятия, нулевые если время не указано
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// LessonAt пара относительно заданного момента времени
+type LessonAt struct {
+	Lesson
+	// Remaining для текущей пары - время до ее конца, для следующей - время до ее начала
+	Remaining time.Duration `json:"remaining"`
+}
+
+// Room кабинет: номер или название и адрес корпуса, если он указан
+type Room struct {
+	Name     string `json:"name"`
+	Location string `json:"location"`
+}
+
+// String возвращает кабинет вместе с адресом, например "Гагарина, 1 -205"
+func (r Room) String() string {
+	return strings.TrimSpace(r.Location + " " + r.Name)
 }
 
 type Option struct {
@@ -25,6 +59,8 @@ type Option struct {
 type Announces struct {
 	Announces []Announce `json:"announces"`
 	LastPage  int        `json:"last_page"`
+	// Stale данные взяты из кэша и могут быть устаревшими, новые запрашиваются в фоне
+	Stale bool `json:"stale,omitempty"`
 }
 
 type Announce struct {
@@ -32,4 +68,6 @@ type Announce struct {
 	Date  string `json:"date"`
 	Title string `json:"title"`
 	Body  string `json:"body"`
+	// Stale объявление, полученное через GetAnnounce, взято из кэша и может быть устаревшим
+	Stale bool `json:"stale,omitempty"`
 }
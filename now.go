@@ -0,0 +1,72 @@
+package hmtpk_parser
+
+import (
+	"context"
+	"time"
+
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+	"github.com/chazari-x/hmtpk_parser/v2/schedule"
+)
+
+// weeksAhead сколько недель, включая текущую, просматривает NextLesson в поисках следующей пары
+const weeksAhead = 2
+
+// CurrentLesson получает пары группы или преподавателя, которые идут в момент at.
+// Пар может быть несколько, если группа занимается по подгруппам. Во время перемены или
+// в день без занятий возвращается пустой список
+func (c *Controller) CurrentLesson(ctx context.Context, kind schedule.Kind, subject string, at time.Time) ([]model.LessonAt, error) {
+	weeklySchedule, err := c.GetSchedule(ctx, kind, subject, at)
+	if err != nil {
+		return nil, err
+	}
+
+	var lessons []model.LessonAt
+	for _, day := range weeklySchedule {
+		for _, lesson := range day.Lessons {
+			if lesson.Start.IsZero() || at.Before(lesson.Start) || !at.Before(lesson.End) {
+				continue
+			}
+
+			lessons = append(lessons, model.LessonAt{Lesson: lesson, Remaining: lesson.End.Sub(at)})
+		}
+	}
+
+	return lessons, nil
+}
+
+// NextLesson получает ближайшие пары группы или преподавателя, которые начнутся после момента at.
+// Если на текущей неделе пар больше нет, поиск продолжается на следующей неделе.
+// Если занятий не найдено, возвращается пустой список
+func (c *Controller) NextLesson(ctx context.Context, kind schedule.Kind, subject string, at time.Time) ([]model.LessonAt, error) {
+	for week := 0; week < weeksAhead; week++ {
+		weeklySchedule, err := c.GetSchedule(ctx, kind, subject, at.AddDate(0, 0, 7*week))
+		if err != nil {
+			return nil, err
+		}
+
+		var lessons []model.LessonAt
+		for _, day := range weeklySchedule {
+			for _, lesson := range day.Lessons {
+				if lesson.Start.IsZero() || !lesson.Start.After(at) {
+					continue
+				}
+
+				if len(lessons) > 0 {
+					if lesson.Start.After(lessons[0].Start) {
+						continue
+					} else if lesson.Start.Before(lessons[0].Start) {
+						lessons = lessons[:0]
+					}
+				}
+
+				lessons = append(lessons, model.LessonAt{Lesson: lesson, Remaining: lesson.Start.Sub(at)})
+			}
+		}
+
+		if len(lessons) > 0 {
+			return lessons, nil
+		}
+	}
+
+	return nil, nil
+}
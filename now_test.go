@@ -0,0 +1,126 @@
+package hmtpk_parser
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chazari-x/hmtpk_parser/v2/schedule"
+	"github.com/chazari-x/hmtpk_parser/v2/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// newWeeksServer отдает сохраненное расписание группы на неделю 18-24 марта 2024
+// и его копию на следующую неделю 25-31 марта
+func newWeeksServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	page, err := os.ReadFile(filepath.Join("testdata", "group_schedule.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nextWeek := strings.NewReplacer(
+		"18 марта", "25 марта", "19 марта", "26 марта", "20 марта", "27 марта", "21 марта", "28 марта",
+		"22 марта", "29 марта", "23 марта", "30 марта", "24 марта", "31 марта",
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		date, err := time.Parse("02.01.2006", r.URL.Query().Get("date_edu1c"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		switch _, week := date.ISOWeek(); week {
+		case 12:
+			_, _ = w.Write(page)
+		case 13:
+			_, _ = w.Write([]byte(nextWeek.Replace(string(page))))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestController_CurrentAndNextLesson(t *testing.T) {
+	srv := newWeeksServer(t)
+	c := NewController(nil, logrus.StandardLogger(), WithBaseURL(srv.URL), WithHTTPClient(srv.Client()))
+
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2024, 3, day, hour, min, 0, 0, utils.Location)
+	}
+
+	tests := []struct {
+		name          string
+		at            time.Time
+		wantCurrent   []string
+		wantRemaining time.Duration
+		wantNext      []string
+		wantNextStart time.Time
+	}{
+		{
+			name:          "during lesson",
+			at:            at(18, 9, 0),
+			wantCurrent:   []string{"Математика"},
+			wantRemaining: time.Hour,
+			wantNext:      []string{"Информатика", "Информатика"},
+			wantNextStart: at(18, 10, 10),
+		},
+		{
+			name:          "break before subgroups",
+			at:            at(18, 10, 5),
+			wantNext:      []string{"Информатика", "Информатика"},
+			wantNextStart: at(18, 10, 10),
+		},
+		{
+			name:          "empty day",
+			at:            at(21, 11, 0),
+			wantNext:      []string{"Основы алгоритмизации"},
+			wantNextStart: at(22, 8, 30),
+		},
+		{
+			name:          "weekend rolls over to next week",
+			at:            at(24, 12, 0),
+			wantNext:      []string{"Математика"},
+			wantNextStart: at(25, 8, 30),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, err := c.CurrentLesson(context.Background(), schedule.Group, "114808", tt.at)
+			if err != nil {
+				t.Fatalf("CurrentLesson() error = %v", err)
+			}
+			if len(current) != len(tt.wantCurrent) {
+				t.Fatalf("CurrentLesson() got = %v, want %v", current, tt.wantCurrent)
+			}
+			for i, lesson := range current {
+				if lesson.Name != tt.wantCurrent[i] || lesson.Remaining != tt.wantRemaining {
+					t.Errorf("CurrentLesson() got = %s (%v), want %s (%v)", lesson.Name, lesson.Remaining, tt.wantCurrent[i], tt.wantRemaining)
+				}
+			}
+
+			next, err := c.NextLesson(context.Background(), schedule.Group, "114808", tt.at)
+			if err != nil {
+				t.Fatalf("NextLesson() error = %v", err)
+			}
+			if len(next) != len(tt.wantNext) {
+				t.Fatalf("NextLesson() got = %v, want %v", next, tt.wantNext)
+			}
+			for i, lesson := range next {
+				if lesson.Name != tt.wantNext[i] || !lesson.Start.Equal(tt.wantNextStart) || lesson.Remaining != tt.wantNextStart.Sub(tt.at) {
+					t.Errorf("NextLesson() got = %s at %v, want %s at %v", lesson.Name, lesson.Start, tt.wantNext[i], tt.wantNextStart)
+				}
+			}
+		})
+	}
+}
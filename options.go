@@ -0,0 +1,112 @@
+package hmtpk_parser
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/chazari-x/hmtpk_parser/v2/swr"
+)
+
+// Option настраивает Controller при создании
+type Option func(*config)
+
+type config struct {
+	client      *http.Client
+	baseURL     string
+	concurrency int
+	rateLimit   float64
+	burst       int
+	attempts    int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	threshold   int
+	cooldown    time.Duration
+	cachePolicy CachePolicy
+}
+
+// DefaultConcurrency количество одновременных запросов к сайту при получении расписания на несколько недель
+const DefaultConcurrency = 4
+
+// WithHTTPClient задает http.Client, через который выполняются все запросы к сайту
+// (тайм-ауты, прокси, собственный транспорт)
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *config) {
+		c.client = client
+	}
+}
+
+// WithBaseURL задает адрес сайта колледжа, например зеркала или локального httptest.Server.
+// По умолчанию используется https://hmtpk.ru
+func WithBaseURL(baseURL string) Option {
+	return func(c *config) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithConcurrency задает наибольшее количество одновременных запросов к сайту,
+// которые Controller выполняет при получении расписания на несколько недель
+func WithConcurrency(concurrency int) Option {
+	return func(c *config) {
+		c.concurrency = concurrency
+	}
+}
+
+// WithRateLimit ограничивает частоту запросов к сайту: не больше rps запросов в секунду
+// и не больше burst запросов подряд. Ограничение общее для расписаний и объявлений.
+// По умолчанию частота не ограничена
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *config) {
+		c.rateLimit, c.burst = rps, burst
+	}
+}
+
+// WithRetry задает количество попыток запроса к сайту и задержки между ними. Повторяются
+// запросы, которые не дошли до сайта, и ответы 429 и 5xx, attempts = 1 отключает повторы.
+// По умолчанию 3 попытки с задержкой от 200ms до 5s
+func WithRetry(attempts int, backoff, maxBackoff time.Duration) Option {
+	return func(c *config) {
+		c.attempts, c.backoff, c.maxBackoff = attempts, backoff, maxBackoff
+	}
+}
+
+// WithCircuitBreaker задает размыкатель цепи: после threshold неудачных запросов подряд запросы
+// к сайту на cooldown не выполняются и сразу завершаются ошибкой errors.ErrorCircuitOpen.
+// threshold = 0 отключает размыкатель. По умолчанию 5 запросов и 30s
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *config) {
+		c.threshold, c.cooldown = threshold, cooldown
+	}
+}
+
+// CachePolicy время жизни данных в кэше по видам, см. swr.Policy. Нулевая swr.Policy
+// отключает кэширование данных этого вида
+type CachePolicy struct {
+	// CurrentWeek расписание на текущую неделю
+	CurrentWeek swr.Policy
+	// PastWeeks расписание на прошедшие недели, которое почти не меняется
+	PastWeeks swr.Policy
+	// FutureWeeks расписание на следующие недели
+	FutureWeeks swr.Policy
+	// Options списки групп и преподавателей
+	Options swr.Policy
+	// Announces страницы объявлений
+	Announces swr.Policy
+}
+
+// DefaultCachePolicy возвращает время жизни данных в кэше по умолчанию
+func DefaultCachePolicy() CachePolicy {
+	return CachePolicy{
+		CurrentWeek: swr.Policy{Fresh: time.Minute * 5, Stale: time.Hour * 24},
+		PastWeeks:   swr.Policy{Fresh: time.Hour * 24 * 30, Stale: time.Hour * 24 * 30},
+		FutureWeeks: swr.Policy{Fresh: time.Minute * 15, Stale: time.Hour * 24},
+		Options:     swr.Policy{Fresh: time.Hour, Stale: time.Hour * 24 * 7},
+		Announces:   swr.Policy{Fresh: time.Hour, Stale: time.Hour * 24},
+	}
+}
+
+// WithCachePolicy задает время жизни данных в кэше по видам. По умолчанию DefaultCachePolicy
+func WithCachePolicy(policy CachePolicy) Option {
+	return func(c *config) {
+		c.cachePolicy = policy
+	}
+}
@@ -0,0 +1,40 @@
+package hmtpk_parser
+
+import (
+	"io"
+
+	"github.com/chazari-x/hmtpk_parser/v2/announce"
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+	"github.com/chazari-x/hmtpk_parser/v2/schedule/group"
+	"github.com/chazari-x/hmtpk_parser/v2/schedule/teacher"
+)
+
+// ParseGroupSchedule разбирает сохраненную страницу с расписанием группы без обращения к сайту
+func ParseGroupSchedule(r io.Reader) ([]model.Schedule, error) {
+	return group.ParseSchedule(r)
+}
+
+// ParseTeacherSchedule разбирает сохраненную страницу с расписанием преподавателя без обращения к сайту
+func ParseTeacherSchedule(r io.Reader) ([]model.Schedule, error) {
+	return teacher.ParseSchedule(r)
+}
+
+// ParseGroupOptions разбирает сохраненную страницу со списком групп
+func ParseGroupOptions(r io.Reader) ([]model.Option, error) {
+	return group.ParseOptions(r)
+}
+
+// ParseTeacherOptions разбирает сохраненную страницу со списком преподавателей
+func ParseTeacherOptions(r io.Reader) ([]model.Option, error) {
+	return teacher.ParseOptions(r)
+}
+
+// ParseAnnouncePage разбирает сохраненную страницу со списком объявлений
+func ParseAnnouncePage(r io.Reader) (model.Announces, error) {
+	return announce.ParsePage(r)
+}
+
+// ParseAnnounce разбирает сохраненную страницу с одним объявлением
+func ParseAnnounce(r io.Reader) (model.Announce, error) {
+	return announce.ParseAnnounce(r)
+}
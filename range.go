@@ -0,0 +1,107 @@
+package hmtpk_parser
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/chazari-x/hmtpk_parser/v2/errors"
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+	"github.com/chazari-x/hmtpk_parser/v2/schedule"
+	"github.com/chazari-x/hmtpk_parser/v2/utils"
+)
+
+// maxRangeWeeks наибольшее количество недель, которое можно получить за один вызов GetScheduleRange
+const maxRangeWeeks = 53
+
+// GetScheduleRange получает расписание группы или преподавателя на дни с from по to включительно.
+// Недели запрашиваются параллельно, не более WithConcurrency запросов одновременно, и кэшируются
+// так же, как в GetScheduleByGroup и GetScheduleByTeacher. Дни возвращаются по порядку дат
+func (c *Controller) GetScheduleRange(ctx context.Context, kind schedule.Kind, subject string, from, to time.Time) ([]model.Schedule, error) {
+	adapter, err := c.adapter(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to = startOfDay(from), startOfDay(to)
+	if to.Before(from) {
+		return nil, errors.ErrorBadRequest
+	}
+
+	var weeks []time.Time
+	for monday := utils.StartOfWeek(from); !monday.After(to); monday = monday.AddDate(0, 0, 7) {
+		weeks = append(weeks, monday)
+	}
+
+	if len(weeks) > maxRangeWeeks {
+		return nil, errors.ErrorBadRequest
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		results  = make([][]model.Schedule, len(weeks))
+		sem      = make(chan struct{}, c.concurrency)
+	)
+	for i, monday := range weeks {
+		wg.Add(1)
+		go func(i int, monday time.Time) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			weeklySchedule, err := c.getSchedule(ctx, subject, utils.FormatDate(monday), adapter)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+
+			results[i] = weeklySchedule
+		}(i, monday)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var days []model.Schedule
+	for _, weeklySchedule := range results {
+		for _, day := range weeklySchedule {
+			if day.Date.IsZero() || day.Date.Before(from) || day.Date.After(to) {
+				continue
+			}
+
+			days = append(days, day)
+		}
+	}
+
+	sort.SliceStable(days, func(i, j int) bool {
+		return days[i].Date.Before(days[j].Date)
+	})
+
+	return days, nil
+}
+
+// startOfDay возвращает начало дня, в который входит t, в часовом поясе колледжа
+func startOfDay(t time.Time) time.Time {
+	t = t.In(utils.Location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, utils.Location)
+}
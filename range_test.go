@@ -0,0 +1,58 @@
+package hmtpk_parser
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	hmtpkErrors "github.com/chazari-x/hmtpk_parser/v2/errors"
+	"github.com/chazari-x/hmtpk_parser/v2/schedule"
+	"github.com/chazari-x/hmtpk_parser/v2/storage"
+	"github.com/chazari-x/hmtpk_parser/v2/utils"
+	"github.com/sirupsen/logrus"
+)
+
+func TestController_GetScheduleRange(t *testing.T) {
+	srv := newWeeksServer(t)
+	cache := storage.NewMemory(0)
+	c := NewController(cache, logrus.StandardLogger(), WithBaseURL(srv.URL), WithHTTPClient(srv.Client()), WithConcurrency(2))
+
+	day := func(day int) time.Time {
+		return time.Date(2024, 3, day, 0, 0, 0, 0, utils.Location)
+	}
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		wantDays int
+		wantErr  error
+	}{
+		{name: "two weeks", from: day(20).Add(time.Hour * 15), to: day(27), wantDays: 8},
+		{name: "one day", from: day(25), to: day(25), wantDays: 1},
+		{name: "reversed", from: day(27), to: day(20), wantErr: hmtpkErrors.ErrorBadRequest},
+		{name: "too long", from: day(1), to: day(1).AddDate(2, 0, 0), wantErr: hmtpkErrors.ErrorBadRequest},
+		{name: "upstream error", from: day(20), to: day(20).AddDate(0, 1, 0), wantErr: hmtpkErrors.ErrorBadResponse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.GetScheduleRange(context.Background(), schedule.Group, "114808", tt.from, tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetScheduleRange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != tt.wantDays {
+				t.Fatalf("GetScheduleRange() days = %d, want %d", len(got), tt.wantDays)
+			}
+			for i, day := range got {
+				if want := startOfDay(tt.from).AddDate(0, 0, i); !day.Date.Equal(want) {
+					t.Errorf("GetScheduleRange() day %d = %v, want %v", i, day.Date, want)
+				}
+			}
+		})
+	}
+
+	// Недели сохраняются в кэш под теми же ключами, что и при получении одной недели
+	if _, err := cache.Get(context.Background(), "2024/13:114808"); err != nil {
+		t.Errorf("GetScheduleRange() did not cache week 13: %v", err)
+	}
+}
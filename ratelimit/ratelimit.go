@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter ограничивает частоту запросов алгоритмом token bucket: корзина вмещает burst токенов
+// и пополняется со скоростью rps токенов в секунду, каждый запрос забирает один токен.
+// Нулевой *Limiter ничего не ограничивает, поэтому его можно передавать как "без ограничений"
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// New создает ограничитель на rps запросов в секунду с запасом burst запросов подряд.
+// Если rps не больше нуля, возвращается nil - без ограничений
+func New(rps float64, burst int) *Limiter {
+	if rps <= 0 {
+		return nil
+	}
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &Limiter{rate: rps, burst: float64(burst), tokens: float64(burst), now: time.Now}
+}
+
+// Wait ждет, пока появится свободный токен, или пока не будет отменен ctx
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil || ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	for {
+		wait := l.reserve()
+		if wait == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// Allow забирает токен, если он есть, и не ждет
+func (l *Limiter) Allow() bool {
+	return l == nil || l.reserve() == 0
+}
+
+// reserve забирает токен и возвращает 0 или возвращает время, через которое токен появится
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
@@ -0,0 +1,64 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC)
+
+	l := New(2, 2)
+	l.now = func() time.Time { return now }
+
+	tests := []struct {
+		name  string
+		after time.Duration
+		want  bool
+	}{
+		{name: "burst 1", want: true},
+		{name: "burst 2", want: true},
+		{name: "empty", want: false},
+		{name: "half token", after: 250 * time.Millisecond, want: false},
+		{name: "refilled", after: 250 * time.Millisecond, want: true},
+		{name: "capped by burst 1", after: time.Hour, want: true},
+		{name: "capped by burst 2", want: true},
+		{name: "capped by burst 3", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.after)
+			if got := l.Allow(); got != tt.want {
+				t.Errorf("Allow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimiter_Wait(t *testing.T) {
+	l := New(50, 1)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("Wait() 3 tokens took %v, want at least 30ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := New(0.001, 1).Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() error = %v, want %v", err, context.Canceled)
+	}
+
+	var unlimited *Limiter
+	if err := unlimited.Wait(context.Background()); err != nil {
+		t.Errorf("nil Wait() error = %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package hmtpk_parser
+
+import (
+	"context"
+	"time"
+
+	"github.com/chazari-x/hmtpk_parser/v2/errors"
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+	"github.com/chazari-x/hmtpk_parser/v2/rooms"
+	"github.com/chazari-x/hmtpk_parser/v2/utils"
+)
+
+// RoomIndex обходит расписания всех преподавателей на неделю, в которую входит date,
+// и строит по ним индекс занятости кабинетов. Преподаватели, расписание которых получить
+// не удалось, пропускаются и перечисляются в Index.Incomplete
+func (c *Controller) RoomIndex(ctx context.Context, date time.Time) (*rooms.Index, error) {
+	teachers, failed, err := c.crawlTeachers(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+
+	index := rooms.NewIndex(teachers)
+	index.Incomplete = failed
+
+	return index, nil
+}
+
+// FreeRooms возвращает кабинеты по адресу location, в которых в день date на паре pair нет занятий.
+// Если location пустой, возвращаются кабинеты во всех корпусах. incomplete - преподаватели, расписание
+// которых получить не удалось: если список не пуст, часть занятых кабинетов может оказаться среди свободных
+func (c *Controller) FreeRooms(ctx context.Context, date time.Time, pair int, location string) (free []model.Room, incomplete []string, err error) {
+	if pair < 1 {
+		return nil, nil, errors.ErrorBadRequest
+	}
+
+	index, err := c.RoomIndex(ctx, date)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return index.Free(startOfDay(date), pair, location), index.Incomplete, nil
+}
+
+// GetScheduleByRoom по кабинету и дате получает расписание кабинета на неделю. Своего расписания
+// кабинетов на сайте нет, поэтому оно собирается из расписаний всех преподавателей.
+// Кабинет указывается номером ("-205") или вместе с адресом ("Гагарина, 1 -205").
+// incomplete - преподаватели, расписание которых получить не удалось: если список не пуст,
+// в расписании кабинета может не хватать занятий
+func (c *Controller) GetScheduleByRoom(ctx context.Context, room, date string) (weeklySchedule []model.Schedule, incomplete []string, err error) {
+	d, err := time.ParseInLocation("02.01.2006", date, utils.Location)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	index, err := c.RoomIndex(ctx, d)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	found, err := index.Find(room)
+	if err != nil {
+		return nil, index.Incomplete, err
+	}
+
+	return index.Schedule(found), index.Incomplete, nil
+}
@@ -0,0 +1,234 @@
+package rooms
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chazari-x/hmtpk_parser/v2/errors"
+	"github.com/chazari-x/hmtpk_parser/v2/lookup"
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+)
+
+const dayLayout = "2006-01-02"
+
+// Slot пара в конкретный день. По Slot и model.Room индекс и проверка расписания
+// определяют, что занятия проходят в одном кабинете в одно время
+type Slot struct {
+	Day  string
+	Pair int
+}
+
+// SlotOf возвращает пару pair дня date
+func SlotOf(date time.Time, pair int) Slot {
+	return Slot{Day: date.Format(dayLayout), Pair: pair}
+}
+
+// Index занятость кабинетов за неделю, построенная по расписаниям преподавателей.
+// Список известных кабинетов составляется из всех кабинетов, встретившихся в расписаниях
+type Index struct {
+	rooms   map[model.Room]bool
+	busy    map[Slot]map[model.Room][]model.Lesson
+	days    map[string]model.Schedule
+	lessons map[model.Room]map[string][]model.Lesson
+	// Incomplete преподаватели, расписание которых получить не удалось.
+	// Если список не пуст, часть занятых кабинетов может считаться свободной
+	Incomplete []string
+}
+
+// NewIndex строит индекс по расписаниям преподавателей на неделю, ключ - ФИО преподавателя.
+// В пары индекса записывается преподаватель, так как на его странице он не указан
+func NewIndex(teachers map[string][]model.Schedule) *Index {
+	index := &Index{
+		rooms:   make(map[model.Room]bool),
+		busy:    make(map[Slot]map[model.Room][]model.Lesson),
+		days:    make(map[string]model.Schedule),
+		lessons: make(map[model.Room]map[string][]model.Lesson),
+	}
+
+	for teacher, weeklySchedule := range teachers {
+		for _, day := range weeklySchedule {
+			if day.Date.IsZero() {
+				continue
+			}
+
+			date := day.Date.Format(dayLayout)
+			if _, ok := index.days[date]; !ok {
+				index.days[date] = model.Schedule{Date: day.Date, Weekday: day.Weekday, Title: day.Title}
+			}
+
+			for _, lesson := range day.Lessons {
+				room := RoomOf(lesson)
+				if room.Name == "" {
+					continue
+				}
+
+				if lesson.Teacher == "" {
+					lesson.Teacher = teacher
+				}
+
+				index.rooms[room] = true
+				if index.lessons[room] == nil {
+					index.lessons[room] = make(map[string][]model.Lesson)
+				}
+				index.lessons[room][date] = append(index.lessons[room][date], lesson)
+
+				if lesson.Pair == 0 {
+					continue
+				}
+
+				key := SlotOf(day.Date, lesson.Pair)
+				if index.busy[key] == nil {
+					index.busy[key] = make(map[model.Room][]model.Lesson)
+				}
+				index.busy[key][room] = append(index.busy[key][room], lesson)
+			}
+		}
+	}
+
+	return index
+}
+
+// RoomOf возвращает кабинет, в котором проходит пара
+func RoomOf(lesson model.Lesson) model.Room {
+	return model.Room{Name: strings.TrimSpace(lesson.Room), Location: strings.TrimSpace(lesson.Location)}
+}
+
+// Rooms возвращает известные кабинеты по адресу location, если location пустой - все кабинеты
+func (i *Index) Rooms(location string) []model.Room {
+	var rooms []model.Room
+	for room := range i.rooms {
+		if matchLocation(room, location) {
+			rooms = append(rooms, room)
+		}
+	}
+
+	sortRooms(rooms)
+
+	return rooms
+}
+
+// Free возвращает кабинеты по адресу location, в которых в день date на паре pair нет занятий
+func (i *Index) Free(date time.Time, pair int, location string) []model.Room {
+	busy := i.busy[SlotOf(date, pair)]
+
+	var rooms []model.Room
+	for room := range i.rooms {
+		if matchLocation(room, location) && len(busy[room]) == 0 {
+			rooms = append(rooms, room)
+		}
+	}
+
+	sortRooms(rooms)
+
+	return rooms
+}
+
+// Busy возвращает пары, которые проходят в кабинете room в день date на паре pair
+func (i *Index) Busy(date time.Time, pair int, room model.Room) []model.Lesson {
+	return i.busy[SlotOf(date, pair)][room]
+}
+
+// Find ищет известный кабинет по номеру ("205") или по адресу и номеру ("Гагарина, 1 -205").
+// Если кабинет не найден, возвращается errors.ErrorNotFound, если подходит несколько - errors.ErrorAmbiguous
+func (i *Index) Find(query string) (model.Room, error) {
+	q := lookup.Normalize(query)
+	if q == "" {
+		return model.Room{}, errors.ErrorBadRequest
+	}
+
+	var found []model.Room
+	for room := range i.rooms {
+		if q == lookup.Normalize(room.Name) || q == lookup.Normalize(room.Location+" "+room.Name) {
+			found = append(found, room)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return model.Room{}, errors.ErrorNotFound
+	case 1:
+		return found[0], nil
+	default:
+		return model.Room{}, errors.ErrorAmbiguous
+	}
+}
+
+// Schedule возвращает расписание кабинета room на неделю. Пары, которые преподаватель ведет
+// у нескольких подгрупп или групп одновременно, объединяются в одну, группы перечисляются через запятую
+func (i *Index) Schedule(room model.Room) []model.Schedule {
+	var weeklySchedule []model.Schedule
+	for date, day := range i.days {
+		day.Lessons = merge(i.lessons[room][date])
+		weeklySchedule = append(weeklySchedule, day)
+	}
+
+	sort.Slice(weeklySchedule, func(a, b int) bool {
+		return weeklySchedule[a].Date.Before(weeklySchedule[b].Date)
+	})
+
+	return weeklySchedule
+}
+
+// merge объединяет одинаковые пары одного преподавателя и сортирует пары по времени
+func merge(lessons []model.Lesson) []model.Lesson {
+	var merged []model.Lesson
+	for _, lesson := range lessons {
+		duplicate := false
+		for n := range merged {
+			if merged[n].Num != lesson.Num || merged[n].Time != lesson.Time ||
+				merged[n].Name != lesson.Name || merged[n].Teacher != lesson.Teacher {
+				continue
+			}
+
+			if !containsGroup(merged[n].Group, lesson.Group) {
+				merged[n].Group += ", " + lesson.Group
+			}
+			if merged[n].Subgroup != lesson.Subgroup {
+				merged[n].Subgroup = ""
+			}
+
+			duplicate = true
+			break
+		}
+
+		if !duplicate {
+			merged = append(merged, lesson)
+		}
+	}
+
+	sort.SliceStable(merged, func(a, b int) bool {
+		if merged[a].Pair != merged[b].Pair {
+			return merged[a].Pair < merged[b].Pair
+		}
+
+		return merged[a].Teacher < merged[b].Teacher
+	})
+
+	return merged
+}
+
+func containsGroup(groups, group string) bool {
+	for _, g := range strings.Split(groups, ", ") {
+		if g == group {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchLocation(room model.Room, location string) bool {
+	location = strings.TrimSpace(location)
+	return location == "" || strings.EqualFold(room.Location, location)
+}
+
+func sortRooms(rooms []model.Room) {
+	sort.Slice(rooms, func(i, j int) bool {
+		if rooms[i].Location != rooms[j].Location {
+			return rooms[i].Location < rooms[j].Location
+		}
+
+		return rooms[i].Name < rooms[j].Name
+	})
+}
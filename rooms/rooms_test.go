@@ -0,0 +1,124 @@
+package rooms
+
+import (
+	stderrors "errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/chazari-x/hmtpk_parser/v2/errors"
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+)
+
+func TestIndex(t *testing.T) {
+	monday := time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)
+	tuesday := monday.AddDate(0, 0, 1)
+
+	index := NewIndex(map[string][]model.Schedule{
+		"Иванов Иван Иванович": {{
+			Date: monday,
+			Lessons: []model.Lesson{
+				{Pair: 1, Name: "Математика", Group: "ИС-21", Room: "-205", Location: "Гагарина, 1"},
+				{Pair: 2, Name: "Математика", Group: "ИС-22", Room: "-207", Location: "Гагарина, 1"},
+			},
+		}},
+		"Петрова Анна Сергеевна": {{
+			Date: tuesday,
+			Lessons: []model.Lesson{
+				{Pair: 1, Name: "Физика", Group: "ПК-31", Room: "-12", Location: "Мира, 15"},
+				{Name: "Консультация", Room: "Спортзал"},
+			},
+		}},
+	})
+
+	gagarina205 := model.Room{Name: "-205", Location: "Гагарина, 1"}
+	gagarina207 := model.Room{Name: "-207", Location: "Гагарина, 1"}
+	mira12 := model.Room{Name: "-12", Location: "Мира, 15"}
+	gym := model.Room{Name: "Спортзал"}
+
+	tests := []struct {
+		name     string
+		date     time.Time
+		pair     int
+		location string
+		want     []model.Room
+	}{
+		{name: "all locations", date: monday, pair: 1, want: []model.Room{gym, gagarina207, mira12}},
+		{name: "location", date: monday, pair: 1, location: "гагарина, 1", want: []model.Room{gagarina207}},
+		{name: "other day", date: tuesday, pair: 1, location: "Мира, 15"},
+		{name: "no lessons", date: monday, pair: 5, location: "Гагарина, 1", want: []model.Room{gagarina205, gagarina207}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := index.Free(tt.date, tt.pair, tt.location); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Free() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	busy := index.Busy(monday, 1, gagarina205)
+	if len(busy) != 1 || busy[0].Teacher != "Иванов Иван Иванович" {
+		t.Errorf("Busy() = %+v, want lesson of Иванов Иван Иванович", busy)
+	}
+}
+
+func TestIndex_Schedule(t *testing.T) {
+	monday := time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)
+	room := model.Room{Name: "-205", Location: "Гагарина, 1"}
+
+	index := NewIndex(map[string][]model.Schedule{
+		"Иванов Иван Иванович": {
+			{
+				Date:  monday,
+				Title: "18 марта 2024, Понедельник",
+				Lessons: []model.Lesson{
+					{Num: "2", Pair: 2, Name: "Информатика", Group: "ИС-21", Subgroup: "2", Room: "-205", Location: "Гагарина, 1"},
+					{Num: "2", Pair: 2, Name: "Информатика", Group: "ИС-21", Subgroup: "1", Room: "-205", Location: "Гагарина, 1"},
+					{Num: "3", Pair: 3, Name: "Информатика", Group: "ИС-22", Room: "-205", Location: "Гагарина, 1"},
+					{Num: "3", Pair: 3, Name: "Информатика", Group: "ИС-21", Room: "-205", Location: "Гагарина, 1"},
+				},
+			},
+			{Date: monday.AddDate(0, 0, 1), Title: "19 марта 2024, Вторник"},
+		},
+		"Петрова Анна Сергеевна": {{
+			Date:    monday,
+			Title:   "18 марта 2024, Понедельник",
+			Lessons: []model.Lesson{{Num: "1", Pair: 1, Name: "Физика", Group: "ПК-31", Room: "205", Location: "Мира, 15"}},
+		}},
+	})
+
+	got := index.Schedule(room)
+	if len(got) != 2 || len(got[1].Lessons) != 0 {
+		t.Fatalf("Schedule() = %+v, want two days with lessons on the first one", got)
+	}
+
+	want := []model.Lesson{
+		{Num: "2", Pair: 2, Name: "Информатика", Group: "ИС-21", Room: "-205", Location: "Гагарина, 1", Teacher: "Иванов Иван Иванович"},
+		{Num: "3", Pair: 3, Name: "Информатика", Group: "ИС-22, ИС-21", Room: "-205", Location: "Гагарина, 1", Teacher: "Иванов Иван Иванович"},
+	}
+	if !reflect.DeepEqual(got[0].Lessons, want) {
+		t.Errorf("Schedule() lessons = %+v, want %+v", got[0].Lessons, want)
+	}
+
+	tests := []struct {
+		query   string
+		want    model.Room
+		wantErr error
+	}{
+		{query: "Гагарина, 1 -205", want: room},
+		{query: "мира 15 205", want: model.Room{Name: "205", Location: "Мира, 15"}},
+		{query: "205", wantErr: errors.ErrorAmbiguous},
+		{query: "101", wantErr: errors.ErrorNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := index.Find(tt.query)
+			if !stderrors.Is(err, tt.wantErr) {
+				t.Fatalf("Find() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Find() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
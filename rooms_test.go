@@ -0,0 +1,114 @@
+package hmtpk_parser
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	hmtpkErrors "github.com/chazari-x/hmtpk_parser/v2/errors"
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+	"github.com/chazari-x/hmtpk_parser/v2/utils"
+	"github.com/sirupsen/logrus"
+)
+
+func TestController_FreeRooms(t *testing.T) {
+	srv := newTestServer(t)
+	c := NewController(nil, logrus.StandardLogger(), WithBaseURL(srv.URL), WithHTTPClient(srv.Client()))
+
+	monday := time.Date(2024, 3, 18, 9, 0, 0, 0, utils.Location)
+
+	tests := []struct {
+		name     string
+		date     time.Time
+		pair     int
+		location string
+		want     []model.Room
+		wantErr  error
+	}{
+		{
+			name:     "busy room excluded",
+			date:     monday,
+			pair:     1,
+			location: "Гагарина, 1",
+			want:     []model.Room{{Name: "-207", Location: "Гагарина, 1"}},
+		},
+		{
+			name: "all locations",
+			date: monday.AddDate(0, 0, 4),
+			pair: 5,
+			want: []model.Room{
+				{Name: "-205", Location: "Гагарина, 1"},
+				{Name: "-207", Location: "Гагарина, 1"},
+				{Name: "-12", Location: "Мира, 15"},
+			},
+		},
+		{name: "bad pair", date: monday, wantErr: hmtpkErrors.ErrorBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, incomplete, err := c.FreeRooms(context.Background(), tt.date, tt.pair, tt.location)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FreeRooms() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) || len(incomplete) != 0 {
+				t.Errorf("FreeRooms() got = %v, incomplete %v, want %v", got, incomplete, tt.want)
+			}
+		})
+	}
+}
+
+func TestController_GetScheduleByRoom(t *testing.T) {
+	srv := newTestServer(t)
+	c := NewController(nil, logrus.StandardLogger(), WithBaseURL(srv.URL), WithHTTPClient(srv.Client()))
+
+	got, incomplete, err := c.GetScheduleByRoom(context.Background(), "Гагарина, 1 -207", "20.03.2024")
+	if err != nil || len(incomplete) != 0 {
+		t.Fatalf("GetScheduleByRoom() incomplete = %v, error = %v", incomplete, err)
+	}
+
+	if len(got) != 7 {
+		t.Fatalf("GetScheduleByRoom() got %d days, want 7", len(got))
+	}
+
+	// тестовый сервер отдает одну и ту же страницу для всех четырех преподавателей
+	thursday := got[3]
+	if thursday.Weekday != time.Thursday || len(thursday.Lessons) != 8 {
+		t.Fatalf("GetScheduleByRoom() thursday = %+v, want 8 lessons", thursday)
+	}
+	for _, lesson := range thursday.Lessons {
+		if lesson.Teacher == "" || lesson.Room != "-207" {
+			t.Errorf("GetScheduleByRoom() lesson = %+v, want teacher and room -207", lesson)
+		}
+	}
+
+	if _, _, err = c.GetScheduleByRoom(context.Background(), "Мира, 15 -101", "20.03.2024"); !errors.Is(err, hmtpkErrors.ErrorNotFound) {
+		t.Errorf("GetScheduleByRoom() error = %v, want %v", err, hmtpkErrors.ErrorNotFound)
+	}
+}
+
+func TestController_FreeRoomsIncomplete(t *testing.T) {
+	srv := newTestServer(t)
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("teacher") == "Петрова Анна Сергеевна" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		srv.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(failing.Close)
+
+	c := NewController(nil, logrus.StandardLogger(), WithBaseURL(failing.URL), WithHTTPClient(failing.Client()), WithRetry(1, 0, 0))
+	want := []string{"Петрова Анна Сергеевна"}
+
+	monday := time.Date(2024, 3, 18, 9, 0, 0, 0, utils.Location)
+	if _, incomplete, err := c.FreeRooms(context.Background(), monday, 1, ""); err != nil || !reflect.DeepEqual(incomplete, want) {
+		t.Errorf("FreeRooms() incomplete = %v, error = %v, want %v", incomplete, err, want)
+	}
+	if _, incomplete, err := c.GetScheduleByRoom(context.Background(), "Гагарина, 1 -207", "20.03.2024"); err != nil || !reflect.DeepEqual(incomplete, want) {
+		t.Errorf("GetScheduleByRoom() incomplete = %v, error = %v, want %v", incomplete, err, want)
+	}
+}
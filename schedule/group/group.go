@@ -2,37 +2,30 @@ package group
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/http"
-	"strings"
 	"time"
 
-	"github.com/PuerkitoBio/goquery"
-	"github.com/chazari-x/hmtpk_parser/v2/errors"
+	"github.com/chazari-x/hmtpk_parser/v2/fetch"
 	"github.com/chazari-x/hmtpk_parser/v2/model"
+	"github.com/chazari-x/hmtpk_parser/v2/schedule"
 	"github.com/chazari-x/hmtpk_parser/v2/storage"
+	"github.com/chazari-x/hmtpk_parser/v2/swr"
 	"github.com/chazari-x/hmtpk_parser/v2/utils"
-	"github.com/go-redis/redis/v8"
 	"github.com/sirupsen/logrus"
 )
 
 type Controller struct {
-	r   *storage.Redis
-	log *logrus.Logger
+	loader *swr.Loader
+	policy schedule.CachePolicy
+	fetch  *fetch.Client
+	log    *logrus.Logger
 }
 
-func NewController(client *redis.Client, logger *logrus.Logger) *Controller {
-	return &Controller{r: &storage.Redis{Redis: client}, log: logger}
+func NewController(cache storage.Cache, policy schedule.CachePolicy, client *fetch.Client, logger *logrus.Logger) *Controller {
+	return &Controller{loader: swr.NewLoader(cache, logger), policy: policy, fetch: client, log: logger}
 }
 
-const (
-	firstDayNum  = 2
-	lastDayNum   = firstDayNum + 6
-	numOfColumns = 5
-
-	href = "https://hmtpk.ru/ru/students/schedule"
-)
+const path = "/ru/students/schedule"
 
 func (c *Controller) GetSchedule(ctx context.Context, value, date string) ([]model.Schedule, error) {
 	var weeklySchedule []model.Schedule
@@ -43,49 +36,34 @@ func (c *Controller) GetSchedule(ctx context.Context, value, date string) ([]mod
 	}
 
 	year, week := d.ISOWeek()
-	if utils.RedisIsNil(c.r) {
-		if redisWeeklySchedule, err := c.r.Get(fmt.Sprintf("%d/%d", year, week) + ":" + value); err == nil && redisWeeklySchedule != "" {
-			if json.Unmarshal([]byte(redisWeeklySchedule), &weeklySchedule) == nil {
-				return weeklySchedule, nil
-			}
-		}
-	}
-
-	href := fmt.Sprintf("%s/?group=%s&date_edu1c=%s&send=Показать#current", href, value, date)
-	request, err := http.NewRequestWithContext(ctx, "POST", href, nil)
+	policy := c.policy.Week(d, time.Now())
+	stale, err := c.loader.Load(ctx, fmt.Sprintf("%d/%d", year, week)+":"+value, policy, func(ctx context.Context) (any, error) {
+		return c.fetchSchedule(ctx, value, date)
+	}, &weeklySchedule)
 	if err != nil {
 		return nil, err
 	}
 
-	client := http.Client{}
-	resp, err := client.Do(request)
-	if err != nil {
-		return nil, err
+	for i := range weeklySchedule {
+		weeklySchedule[i].Stale = stale
 	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("%w: %s", errors.ErrorBadResponse, resp.Status)
-	}
+	return weeklySchedule, nil
+}
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+// fetchSchedule получает расписание на неделю с сайта
+func (c *Controller) fetchSchedule(ctx context.Context, value, date string) ([]model.Schedule, error) {
+	doc, err := c.fetch.Document(ctx, c.href(value, date))
 	if err != nil {
 		return nil, err
 	}
 
-	for scheduleElementNum := firstDayNum; scheduleElementNum <= lastDayNum; scheduleElementNum++ {
-		weeklySchedule = append(weeklySchedule, c.parseDay(doc, scheduleElementNum, value))
-	}
-
-	if utils.RedisIsNil(c.r) {
-		if c.r.Redis != nil {
-			if marshal, err := json.Marshal(weeklySchedule); err == nil {
-				if err = c.r.Set(fmt.Sprintf("%d/%d", year, week)+":"+value, string(marshal)); err != nil {
-					c.log.Error(err)
-				}
-			}
+	weeklySchedule := parseWeek(doc)
+	for i := range weeklySchedule {
+		if weeklySchedule[i].Date.IsZero() {
+			weeklySchedule[i].Href = c.href(value, date)
+		} else {
+			weeklySchedule[i].Href = c.href(value, utils.FormatDate(weeklySchedule[i].Date))
 		}
 	}
 
@@ -95,143 +73,25 @@ func (c *Controller) GetSchedule(ctx context.Context, value, date string) ([]mod
 const groupsKey = "groups"
 
 func (c *Controller) GetOptions(ctx context.Context) (options []model.Option, err error) {
-	if utils.RedisIsNil(c.r) {
-		var data string
-		if data, err = c.r.Get(groupsKey); err == nil && data != "" {
-			if json.Unmarshal([]byte(data), &options) == nil && len(options) != 0 {
-				return
-			}
-		}
-	}
-
-	href := fmt.Sprintf("%s/?bxrand=%d", href, time.Now().Unix())
-	request, err := http.NewRequestWithContext(ctx, "POST", href, nil)
-	if err != nil {
-		return
-	}
-
-	client := http.Client{}
-	resp, err := client.Do(request)
-	if err != nil {
-		return
-	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
-
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("%w: %s", errors.ErrorBadResponse, resp.Status)
-	}
-
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	options = c.parseOptions(doc)
-
-	if utils.RedisIsNil(c.r) && len(options) != 0 {
-		var marshal []byte
-		if marshal, err = json.Marshal(options); err == nil {
-			if err = c.r.Set(groupsKey, string(marshal), 60); err != nil {
-				c.log.Error(err)
-			}
-		}
-	}
-
-	return
-}
-
-func (c *Controller) parseOptions(doc *goquery.Document) (options []model.Option) {
-	elements := doc.Children().Find("#group > option[value]")
-	elements.Each(func(i int, s *goquery.Selection) {
-		value, exists := s.Attr("value")
-		if exists {
-			options = append(options, model.Option{Label: s.Text(), Value: value})
-		}
-	})
+	_, err = c.loader.Load(ctx, groupsKey, c.policy.Options, func(ctx context.Context) (any, error) {
+		return c.fetchOptions(ctx)
+	}, &options)
 
 	return
 }
 
-func (c *Controller) parseDay(doc *goquery.Document, scheduleElementNum int, name string) model.Schedule {
-	scheduleDateElement := doc.Children().Find(fmt.Sprintf("div.raspcontent.m5 div:nth-child(%d) div.panel-heading.edu_today > h2", scheduleElementNum))
-
-	date := utils.GetDate(strings.Split(scheduleDateElement.Text(), ",")[0])
-	var schedule = model.Schedule{
-		Date: scheduleDateElement.Text(),
-		Href: fmt.Sprintf("%s/?group=%s&date_edu1c=%s&send=Показать#current", href, name, date),
-	}
-
-	var before string
-
-	lessonsElement := doc.Children().Find(fmt.Sprintf("div.raspcontent.m5 div:nth-child(%d) div.panel-body > #mobile-friendly > tbody:nth-child(2)", scheduleElementNum))
-	for lessonNum := 1; lessonNum > 0; lessonNum++ {
-		if len(schedule.Lessons) > 0 {
-			before = schedule.Lessons[len(schedule.Lessons)-1].Num
-		}
-
-		if lesson, exists := c.parseLesson(lessonsElement, lessonNum, before); exists {
-			schedule.Lessons = append(schedule.Lessons, lesson)
-		} else {
-			break
-		}
-	}
-
-	return schedule
-}
-
-func (c *Controller) parseLesson(lessonsElement *goquery.Selection, lessonNum int, before string) (model.Lesson, bool) {
-	var lesson model.Lesson
-	var exists bool
-	lessonElement := lessonsElement.Find(fmt.Sprintf("tr:nth-child(%d)", lessonNum))
-	for lessonAttributeNum := 1; lessonAttributeNum <= numOfColumns; lessonAttributeNum++ {
-		lesson, exists = c.parseLessonAttribute(lessonElement, lessonAttributeNum, lesson, before)
-		if !exists {
-			break
-		}
+// fetchOptions получает список групп с сайта
+func (c *Controller) fetchOptions(ctx context.Context) ([]model.Option, error) {
+	href := fmt.Sprintf("%s/?bxrand=%d", c.fetch.URL(path), time.Now().Unix())
+	doc, err := c.fetch.Document(ctx, href)
+	if err != nil {
+		return nil, err
 	}
 
-	return lesson, exists
+	return parseOptions(doc), nil
 }
 
-func (c *Controller) parseLessonAttribute(lessonElement *goquery.Selection, lessonAttributeNum int, lesson model.Lesson, before string) (model.Lesson, bool) {
-	lessonElementAttribute := lessonElement.Find(fmt.Sprintf("td:nth-child(%d)", lessonAttributeNum))
-	value, exists := lessonElementAttribute.Attr("data-title")
-	if !exists {
-		if lessonAttributeNum == numOfColumns {
-			return lesson, true
-		} else if lessonAttributeNum == 1 {
-			return lesson, false
-		}
-	}
-
-	text := lessonElementAttribute.Text()
-	switch value {
-	case "Номер урока":
-		lesson.Num = text
-	case "Время":
-		if lesson.Num == "" {
-			lesson.Num = before
-		}
-		lesson.Time = text
-	case "Название предмета":
-		if strings.HasSuffix(text, "(1)") || strings.HasSuffix(text, "(2)") {
-			switch text[len(text)-3:] {
-			case "(1)":
-				lesson.Subgroup = "1"
-			case "(2)":
-				lesson.Subgroup = "2"
-			}
-			lesson.Name = strings.TrimSpace(strings.TrimRight(strings.TrimRight(text, " (2)"), " (1)"))
-		} else {
-			lesson.Name = strings.TrimSpace(text)
-		}
-	case "Кабинет":
-		lesson.Room = text
-	case "Преподаватель":
-		lesson.Teacher = text
-	}
-
-	return lesson, true
+// href возвращает адрес страницы с расписанием на неделю, в которую входит date
+func (c *Controller) href(value, date string) string {
+	return fmt.Sprintf("%s/?group=%s&date_edu1c=%s&send=Показать#current", c.fetch.URL(path), value, date)
 }
@@ -0,0 +1,139 @@
+package group
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+	"github.com/chazari-x/hmtpk_parser/v2/utils"
+)
+
+const (
+	firstDayNum  = 2
+	lastDayNum   = firstDayNum + 6
+	numOfColumns = 5
+)
+
+// ParseSchedule разбирает сохраненную html страницу с расписанием на неделю.
+// Поле Href в результате не заполняется, так как адрес страницы неизвестен
+func ParseSchedule(r io.Reader) ([]model.Schedule, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseWeek(doc), nil
+}
+
+// ParseOptions разбирает сохраненную html страницу со списком для выбора расписания
+func ParseOptions(r io.Reader) ([]model.Option, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseOptions(doc), nil
+}
+
+func parseWeek(doc *goquery.Document) (weeklySchedule []model.Schedule) {
+	for scheduleElementNum := firstDayNum; scheduleElementNum <= lastDayNum; scheduleElementNum++ {
+		weeklySchedule = append(weeklySchedule, parseDay(doc, scheduleElementNum))
+	}
+
+	return
+}
+
+func parseOptions(doc *goquery.Document) (options []model.Option) {
+	elements := doc.Children().Find("#group > option[value]")
+	elements.Each(func(i int, s *goquery.Selection) {
+		value, exists := s.Attr("value")
+		if exists {
+			options = append(options, model.Option{Label: s.Text(), Value: value})
+		}
+	})
+
+	return
+}
+
+func parseDay(doc *goquery.Document, scheduleElementNum int) model.Schedule {
+	scheduleDateElement := doc.Children().Find(fmt.Sprintf("div.raspcontent.m5 div:nth-child(%d) div.panel-heading.edu_today > h2", scheduleElementNum))
+
+	var schedule = model.Schedule{Title: scheduleDateElement.Text()}
+	if date, err := utils.ParseDate(schedule.Title); err == nil {
+		schedule.Date, schedule.Weekday = date, date.Weekday()
+	}
+
+	var before string
+
+	lessonsElement := doc.Children().Find(fmt.Sprintf("div.raspcontent.m5 div:nth-child(%d) div.panel-body > #mobile-friendly > tbody:nth-child(2)", scheduleElementNum))
+	for lessonNum := 1; lessonNum > 0; lessonNum++ {
+		if len(schedule.Lessons) > 0 {
+			before = schedule.Lessons[len(schedule.Lessons)-1].Num
+		}
+
+		if lesson, exists := parseLesson(lessonsElement, lessonNum, before); exists {
+			schedule.Lessons = append(schedule.Lessons, utils.WithLessonTime(lesson, schedule.Date))
+		} else {
+			break
+		}
+	}
+
+	return schedule
+}
+
+func parseLesson(lessonsElement *goquery.Selection, lessonNum int, before string) (model.Lesson, bool) {
+	var lesson model.Lesson
+	var exists bool
+	lessonElement := lessonsElement.Find(fmt.Sprintf("tr:nth-child(%d)", lessonNum))
+	for lessonAttributeNum := 1; lessonAttributeNum <= numOfColumns; lessonAttributeNum++ {
+		lesson, exists = parseLessonAttribute(lessonElement, lessonAttributeNum, lesson, before)
+		if !exists {
+			break
+		}
+	}
+
+	return lesson, exists
+}
+
+func parseLessonAttribute(lessonElement *goquery.Selection, lessonAttributeNum int, lesson model.Lesson, before string) (model.Lesson, bool) {
+	lessonElementAttribute := lessonElement.Find(fmt.Sprintf("td:nth-child(%d)", lessonAttributeNum))
+	value, exists := lessonElementAttribute.Attr("data-title")
+	if !exists {
+		if lessonAttributeNum == numOfColumns {
+			return lesson, true
+		} else if lessonAttributeNum == 1 {
+			return lesson, false
+		}
+	}
+
+	text := lessonElementAttribute.Text()
+	switch value {
+	case "Номер урока":
+		lesson.Num = text
+	case "Время":
+		if lesson.Num == "" {
+			lesson.Num = before
+		}
+		lesson.Time = text
+	case "Название предмета":
+		if strings.HasSuffix(text, "(1)") || strings.HasSuffix(text, "(2)") {
+			switch text[len(text)-3:] {
+			case "(1)":
+				lesson.Subgroup = "1"
+			case "(2)":
+				lesson.Subgroup = "2"
+			}
+			lesson.Name = strings.TrimSpace(strings.TrimRight(strings.TrimRight(text, " (2)"), " (1)"))
+		} else {
+			lesson.Name = strings.TrimSpace(text)
+		}
+	case "Кабинет":
+		lesson.Room = text
+	case "Преподаватель":
+		lesson.Teacher = text
+	}
+
+	return lesson, true
+}
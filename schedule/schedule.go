@@ -2,11 +2,47 @@ package schedule
 
 import (
 	"context"
+	"time"
 
 	"github.com/chazari-x/hmtpk_parser/v2/model"
+	"github.com/chazari-x/hmtpk_parser/v2/swr"
+	"github.com/chazari-x/hmtpk_parser/v2/utils"
 )
 
 type Adapter interface {
 	GetSchedule(ctx context.Context, value, date string) ([]model.Schedule, error)
 	GetOptions(ctx context.Context) ([]model.Option, error)
 }
+
+// Kind вид расписания: группы или преподавателя
+type Kind string
+
+const (
+	Group   Kind = "group"
+	Teacher Kind = "teacher"
+)
+
+// CachePolicy время жизни в кэше расписаний на неделю и списков групп или преподавателей.
+// Нулевая swr.Policy отключает кэширование
+type CachePolicy struct {
+	CurrentWeek swr.Policy
+	// PastWeeks прошедшие недели почти не меняются, поэтому их можно хранить долго
+	PastWeeks   swr.Policy
+	FutureWeeks swr.Policy
+	Options     swr.Policy
+}
+
+// Week возвращает время жизни расписания на неделю, в которую входит date, если сейчас now
+func (p CachePolicy) Week(date, now time.Time) swr.Policy {
+	week := utils.StartOfWeek(time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, utils.Location))
+	current := utils.StartOfWeek(now)
+
+	switch {
+	case week.Before(current):
+		return p.PastWeeks
+	case week.After(current):
+		return p.FutureWeeks
+	default:
+		return p.CurrentWeek
+	}
+}
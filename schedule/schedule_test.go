@@ -0,0 +1,38 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chazari-x/hmtpk_parser/v2/swr"
+	"github.com/chazari-x/hmtpk_parser/v2/utils"
+)
+
+func TestCachePolicy_Week(t *testing.T) {
+	policy := CachePolicy{
+		CurrentWeek: swr.Policy{Fresh: time.Minute},
+		PastWeeks:   swr.Policy{Fresh: time.Hour},
+		FutureWeeks: swr.Policy{Fresh: time.Second},
+	}
+
+	// среда, 20 марта 2024, 01:00 по времени колледжа - еще вторник по UTC
+	now := time.Date(2024, 3, 20, 1, 0, 0, 0, utils.Location)
+
+	tests := []struct {
+		name string
+		date time.Time
+		want swr.Policy
+	}{
+		{name: "monday", date: time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC), want: policy.CurrentWeek},
+		{name: "sunday", date: time.Date(2024, 3, 24, 0, 0, 0, 0, time.UTC), want: policy.CurrentWeek},
+		{name: "past", date: time.Date(2024, 3, 17, 0, 0, 0, 0, time.UTC), want: policy.PastWeeks},
+		{name: "future", date: time.Date(2024, 3, 25, 0, 0, 0, 0, time.UTC), want: policy.FutureWeeks},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Week(tt.date, now); got != tt.want {
+				t.Errorf("Week() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package teacher
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+	"github.com/chazari-x/hmtpk_parser/v2/utils"
+)
+
+const (
+	firstDayNum  = 1
+	lastDayNum   = firstDayNum + 6
+	numOfColumns = 5
+)
+
+// ParseSchedule разбирает сохраненную html страницу с расписанием на неделю.
+// Поле Href в результате не заполняется, так как адрес страницы неизвестен
+func ParseSchedule(r io.Reader) ([]model.Schedule, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseWeek(doc), nil
+}
+
+// ParseOptions разбирает сохраненную html страницу со списком для выбора расписания
+func ParseOptions(r io.Reader) ([]model.Option, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseOptions(doc), nil
+}
+
+func parseWeek(doc *goquery.Document) (weeklySchedule []model.Schedule) {
+	for scheduleElementNum := firstDayNum; scheduleElementNum <= lastDayNum; scheduleElementNum++ {
+		weeklySchedule = append(weeklySchedule, parseDay(doc, scheduleElementNum))
+	}
+
+	return
+}
+
+func parseOptions(doc *goquery.Document) (options []model.Option) {
+	elements := doc.Children().Find("#zstfiltr > div > div:nth-child(1) > select > option[value]:not(:nth-child(2))")
+	elements.Each(func(i int, s *goquery.Selection) {
+		value, exists := s.Attr("value")
+		if exists {
+			options = append(options, model.Option{Label: s.Text(), Value: value})
+		}
+	})
+
+	return
+}
+
+func parseDay(doc *goquery.Document, scheduleElementNum int) model.Schedule {
+	scheduleDateElement := doc.Children().Find(fmt.Sprintf("div.raspcontent.m5 div:nth-child(%d) div.panel-heading.edu_today > h2", scheduleElementNum))
+
+	var schedule = model.Schedule{Title: scheduleDateElement.Text()}
+	if date, err := utils.ParseDate(schedule.Title); err == nil {
+		schedule.Date, schedule.Weekday = date, date.Weekday()
+	}
+
+	lessonsElement := doc.Children().Find(fmt.Sprintf("div.raspcontent.m5 div:nth-child(%d) div.panel-body > table.table > tbody:nth-child(2)", scheduleElementNum))
+	for lessonNum := 1; lessonNum > 0; lessonNum++ {
+		if lesson, exists := parseLesson(lessonsElement, lessonNum, ""); exists {
+			schedule.Lessons = append(schedule.Lessons, utils.WithLessonTime(lesson, schedule.Date))
+		} else {
+			break
+		}
+	}
+
+	return schedule
+}
+
+func parseLesson(lessonsElement *goquery.Selection, lessonNum int, _ string) (model.Lesson, bool) {
+	var lesson model.Lesson
+	var exists bool
+	lessonElement := lessonsElement.Find(fmt.Sprintf("tr:nth-child(%d)", lessonNum))
+	for lessonAttributeNum := 1; lessonAttributeNum <= numOfColumns; lessonAttributeNum++ {
+		lesson, exists = parseLessonAttribute(lessonElement, lessonAttributeNum, lesson, "")
+		if !exists {
+			break
+		}
+	}
+
+	return lesson, exists
+}
+
+func parseLessonAttribute(lessonElement *goquery.Selection, lessonAttributeNum int, lesson model.Lesson, _ string) (model.Lesson, bool) {
+	lessonElementAttribute := lessonElement.Find(fmt.Sprintf("td:nth-child(%d)", lessonAttributeNum))
+	value := lessonElementAttribute.Text()
+	if value == "" {
+		return lesson, lessonAttributeNum != 1
+	}
+
+	value = strings.ReplaceAll(value, "\n", "")
+	value = strings.TrimSpace(value)
+	switch lessonAttributeNum {
+	case 1:
+		lesson.Num = value
+	case 2:
+		lesson.Time = value
+	case 3:
+		if strings.HasSuffix(value, "(1)") || strings.HasSuffix(value, "(2)") {
+			switch value[len(value)-3:] {
+			case "(1)":
+				lesson.Subgroup = "1"
+			case "(2)":
+				lesson.Subgroup = "2"
+			}
+			lesson.Name = strings.TrimRight(strings.TrimRight(value, " (2)"), " (1)")
+		} else {
+			lesson.Name = value
+		}
+	case 4:
+		lesson.Group = value
+	case 5:
+		room := strings.TrimSpace(regexp.MustCompile("\\W-[0-9]{1,3}$").FindString(value))
+		if room == "" {
+			lesson.Room = strings.TrimSpace(value)
+		} else {
+			lesson.Room = room
+			lesson.Location = strings.TrimSpace(strings.TrimRight(value, room))
+		}
+	}
+
+	return lesson, true
+}
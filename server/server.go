@@ -0,0 +1,202 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	hmtpk "github.com/chazari-x/hmtpk_parser/v2"
+	hmtpkErrors "github.com/chazari-x/hmtpk_parser/v2/errors"
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+	"github.com/chazari-x/hmtpk_parser/v2/storage"
+	"github.com/chazari-x/hmtpk_parser/v2/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Handler отдает данные Controller в формате JSON:
+//
+//	GET /groups
+//	GET /teachers
+//	GET /schedule/group/{id}?date=02.01.2006
+//	GET /schedule/teacher/{name}?date=02.01.2006
+//	GET /announces?page=1
+//
+// Если дата не указана, используется текущая дата в часовом поясе колледжа.
+// Пока сайт недоступен и запросы к нему не выполняются (errors.ErrorCircuitOpen), отдается
+// последний удачный ответ на тот же запрос с заголовком Warning, а если его нет - 503
+type Handler struct {
+	controller *hmtpk.Controller
+	log        *logrus.Logger
+	now        func() time.Time
+	lastGood   storage.Cache
+}
+
+// lastGoodTTL время, в течение которого хранится последний удачный ответ
+const lastGoodTTL = time.Hour * 24
+
+// NewHandler создает http.Handler поверх controller
+func NewHandler(controller *hmtpk.Controller, logger *logrus.Logger) *Handler {
+	return &Handler{controller: controller, log: logger, now: time.Now, lastGood: storage.NewMemory(0)}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		h.writeError(w, http.StatusMethodNotAllowed, errors.New(http.StatusText(http.StatusMethodNotAllowed)))
+		return
+	}
+
+	path := strings.Trim(r.URL.Path, "/")
+	switch {
+	case path == "groups":
+		h.serve(w, r, path, func(ctx context.Context) (any, error) {
+			return h.controller.GetGroupOptions(ctx)
+		})
+	case path == "teachers":
+		h.serve(w, r, path, func(ctx context.Context) (any, error) {
+			return h.controller.GetTeacherOptions(ctx)
+		})
+	case strings.HasPrefix(path, "schedule/group/"):
+		h.serveSchedule(w, r, "schedule/group/", strings.TrimPrefix(path, "schedule/group/"), h.controller.GetScheduleByGroupDate)
+	case strings.HasPrefix(path, "schedule/teacher/"):
+		h.serveSchedule(w, r, "schedule/teacher/", strings.TrimPrefix(path, "schedule/teacher/"), h.controller.GetScheduleByTeacherDate)
+	case path == "announces":
+		page := 1
+		if value := r.URL.Query().Get("page"); value != "" {
+			var err error
+			if page, err = strconv.Atoi(value); err != nil {
+				h.writeError(w, http.StatusBadRequest, hmtpkErrors.ErrorBadRequest)
+				return
+			}
+		}
+
+		h.serve(w, r, path+"?page="+strconv.Itoa(page), func(ctx context.Context) (any, error) {
+			return h.controller.GetAnnounces(ctx, page)
+		})
+	default:
+		h.writeError(w, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+	}
+}
+
+func (h *Handler) serveSchedule(w http.ResponseWriter, r *http.Request, route, subject string, get func(context.Context, string, time.Time) ([]model.Schedule, error)) {
+	date, err := h.parseDate(r.URL.Query().Get("date"))
+	if err != nil || subject == "" {
+		h.writeError(w, http.StatusBadRequest, hmtpkErrors.ErrorBadRequest)
+		return
+	}
+
+	h.serve(w, r, route+subject+"?date="+utils.FormatDate(date), func(ctx context.Context) (any, error) {
+		return get(ctx, subject, date)
+	})
+}
+
+// parseDate разбирает дату в формате сайта или ISO 8601
+func (h *Handler) parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return h.now().In(utils.Location), nil
+	}
+
+	date, err := time.ParseInLocation("02.01.2006", value, utils.Location)
+	if err != nil {
+		return time.ParseInLocation("2006-01-02", value, utils.Location)
+	}
+
+	return date, nil
+}
+
+// serve получает данные и отдает их в формате JSON с заголовком ETag. Последний удачный ответ
+// сохраняется по ключу key, составленному из маршрута и проверенных параметров запроса
+func (h *Handler) serve(w http.ResponseWriter, r *http.Request, key string, get func(ctx context.Context) (any, error)) {
+	data, err := get(r.Context())
+	if errors.Is(err, hmtpkErrors.ErrorCircuitOpen) {
+		if body, cacheErr := h.lastGood.Get(r.Context(), key); cacheErr == nil {
+			w.Header().Set("Warning", `110 - "Response is Stale"`)
+			h.writeBody(w, r, []byte(body))
+			return
+		}
+	}
+	if err != nil {
+		h.writeError(w, statusCode(err), err)
+		return
+	}
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = h.lastGood.Set(r.Context(), key, string(body), lastGoodTTL); err != nil {
+		h.log.Error(err)
+	}
+
+	h.writeBody(w, r, body)
+}
+
+// writeBody отдает JSON с заголовком ETag или 304, если тег совпадает с If-None-Match
+func (h *Handler) writeBody(w http.ResponseWriter, r *http.Request, body []byte) {
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	if match := r.Header.Get("If-None-Match"); match != "" && matchETag(match, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(body)
+	}
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, code int, err error) {
+	if code >= http.StatusInternalServerError {
+		h.log.Error(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(struct {
+		Error string `json:"error"`
+	}{Error: err.Error()})
+}
+
+// statusCode возвращает код ответа для ошибки Controller
+func statusCode(err error) int {
+	switch {
+	case errors.Is(err, hmtpkErrors.ErrorBadRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, hmtpkErrors.ErrorCircuitOpen):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, hmtpkErrors.ErrorBadResponse):
+		return http.StatusBadGateway
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.As(err, new(*url.Error)):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// matchETag проверяет, содержит ли заголовок If-None-Match тег etag
+func matchETag(header, etag string) bool {
+	for _, value := range strings.Split(header, ",") {
+		value = strings.TrimPrefix(strings.TrimSpace(value), "W/")
+		if value == etag || value == "*" {
+			return true
+		}
+	}
+
+	return false
+}
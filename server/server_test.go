@@ -0,0 +1,186 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	hmtpk "github.com/chazari-x/hmtpk_parser/v2"
+	"github.com/chazari-x/hmtpk_parser/v2/breaker"
+	"github.com/chazari-x/hmtpk_parser/v2/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// newUpstream поднимает замену hmtpk.ru: страницы расписания отдаются из testdata, остальные запросы завершаются ошибкой 500
+func newUpstream(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/ru/students/schedule"):
+			http.ServeFile(w, r, filepath.Join("..", "testdata", "group_schedule.html"))
+		case strings.HasPrefix(r.URL.Path, "/ru/teachers/schedule"):
+			http.ServeFile(w, r, filepath.Join("..", "testdata", "teacher_schedule.html"))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestHandler(t *testing.T) {
+	upstream := newUpstream(t)
+	controller := hmtpk.NewController(nil, logrus.StandardLogger(), hmtpk.WithBaseURL(upstream.URL), hmtpk.WithHTTPClient(upstream.Client()), hmtpk.WithRetry(1, 0, 0))
+
+	srv := httptest.NewServer(NewHandler(controller, logrus.StandardLogger()))
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		wantCode int
+		wantBody string
+	}{
+		{name: "groups", path: "/groups", wantCode: http.StatusOK, wantBody: "ИС-21"},
+		{name: "teachers", path: "/teachers", wantCode: http.StatusOK, wantBody: "Петрова Анна Сергеевна"},
+		{name: "group schedule", path: "/schedule/group/114808?date=20.03.2024", wantCode: http.StatusOK, wantBody: "Информатика"},
+		{name: "teacher schedule", path: "/schedule/teacher/Иванов%20Иван%20Иванович?date=2024-03-20", wantCode: http.StatusOK, wantBody: "ПК-31"},
+		{name: "bad group", path: "/schedule/group/0", wantCode: http.StatusBadRequest},
+		{name: "bad date", path: "/schedule/group/114808?date=20/03/2024", wantCode: http.StatusBadRequest},
+		{name: "bad page", path: "/announces?page=0", wantCode: http.StatusBadRequest},
+		{name: "upstream error", path: "/announces?page=1", wantCode: http.StatusBadGateway},
+		{name: "not found", path: "/rooms", wantCode: http.StatusNotFound},
+		{name: "method not allowed", method: http.MethodPost, path: "/groups", wantCode: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.method == "" {
+				tt.method = http.MethodGet
+			}
+
+			code, body, _ := request(t, tt.method, srv.URL+tt.path, "")
+			if code != tt.wantCode {
+				t.Errorf("%s %s code = %d, want %d: %s", tt.method, tt.path, code, tt.wantCode, body)
+			}
+			if !strings.Contains(body, tt.wantBody) {
+				t.Errorf("%s %s body = %s, want %s", tt.method, tt.path, body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestHandler_ETag(t *testing.T) {
+	upstream := newUpstream(t)
+	controller := hmtpk.NewController(nil, logrus.StandardLogger(), hmtpk.WithBaseURL(upstream.URL), hmtpk.WithHTTPClient(upstream.Client()))
+
+	srv := httptest.NewServer(NewHandler(controller, logrus.StandardLogger()))
+	defer srv.Close()
+
+	url := srv.URL + "/schedule/group/114808?date=20.03.2024"
+	code, _, etag := request(t, http.MethodGet, url, "")
+	if code != http.StatusOK || etag == "" {
+		t.Fatalf("GET code = %d, ETag = %q", code, etag)
+	}
+
+	if code, body, _ := request(t, http.MethodGet, url, etag); code != http.StatusNotModified || body != "" {
+		t.Errorf("GET with If-None-Match code = %d, body = %q, want 304 without body", code, body)
+	}
+
+	if code, _, _ := request(t, http.MethodGet, url, `"other"`); code != http.StatusOK {
+		t.Errorf("GET with stale If-None-Match code = %d, want 200", code)
+	}
+}
+
+func TestHandler_CircuitOpen(t *testing.T) {
+	var down atomic.Bool
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		http.ServeFile(w, r, filepath.Join("..", "testdata", "group_schedule.html"))
+	}))
+	defer upstream.Close()
+
+	controller := hmtpk.NewController(nil, logrus.StandardLogger(),
+		hmtpk.WithBaseURL(upstream.URL),
+		hmtpk.WithHTTPClient(upstream.Client()),
+		hmtpk.WithRetry(1, 0, 0),
+		hmtpk.WithCircuitBreaker(1, time.Hour),
+	)
+
+	handler := NewHandler(controller, logrus.StandardLogger())
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	cached := srv.URL + "/schedule/group/114808?date=20.03.2024"
+	if code, _, _ := request(t, http.MethodGet, cached, ""); code != http.StatusOK {
+		t.Fatalf("GET code = %d, want 200", code)
+	}
+
+	down.Store(true)
+
+	tests := []struct {
+		name     string
+		url      string
+		wantCode int
+		wantBody string
+	}{
+		{name: "upstream error opens circuit", url: srv.URL + "/schedule/group/114808?date=27.03.2024", wantCode: http.StatusBadGateway},
+		{name: "last good payload", url: cached, wantCode: http.StatusOK, wantBody: "Информатика"},
+		{name: "same date in other format", url: srv.URL + "/schedule/group/114808?date=2024-03-20&utm=1", wantCode: http.StatusOK, wantBody: "Информатика"},
+		{name: "nothing to serve", url: srv.URL + "/schedule/group/114809?date=20.03.2024", wantCode: http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body, _ := request(t, http.MethodGet, tt.url, "")
+			if code != tt.wantCode || !strings.Contains(body, tt.wantBody) {
+				t.Errorf("GET code = %d, body = %s, want %d %s", code, body, tt.wantCode, tt.wantBody)
+			}
+		})
+	}
+
+	if n := handler.lastGood.(*storage.Memory).Len(); n != 1 {
+		t.Errorf("last good payloads = %d, want 1", n)
+	}
+
+	if state := controller.CircuitState(); state != breaker.Open {
+		t.Errorf("CircuitState() = %v, want %v", state, breaker.Open)
+	}
+}
+
+func request(t *testing.T, method, url, ifNoneMatch string) (int, string, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	var body strings.Builder
+	if _, err = io.Copy(&body, resp.Body); err != nil {
+		t.Fatal(err)
+	}
+
+	return resp.StatusCode, body.String(), resp.Header.Get("ETag")
+}
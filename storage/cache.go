@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound возвращается, если ключ отсутствует в кэше или его время жизни истекло
+var ErrNotFound = errors.New("key not found")
+
+// Cache описывает хранилище, в котором кэшируются данные с сайта hmtpk.ru
+type Cache interface {
+	// Get получает значение по ключу
+	Get(ctx context.Context, key string) (string, error)
+	// Set устанавливает значение по ключу с временем жизни ttl
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	// Delete удаляет значение по ключу
+	Delete(ctx context.Context, key string) error
+}
+
+// Lister хранилище, которое может перечислить свои ключи. Реализуется Memory и Redis
+type Lister interface {
+	// Keys возвращает ключи, которые начинаются с prefix и время жизни которых не истекло
+	Keys(ctx context.Context, prefix string) ([]string, error)
+}
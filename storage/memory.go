@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMemoryCapacity количество ключей, которое по умолчанию хранит Memory
+const DefaultMemoryCapacity = 1024
+
+// Memory хранит значения в памяти процесса и вытесняет давно неиспользуемые ключи (LRU)
+type Memory struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+	now      func() time.Time
+}
+
+type memoryItem struct {
+	key       string
+	value     string
+	expiresAt time.Time
+}
+
+// NewMemory создает кэш в памяти, который хранит не более capacity ключей
+func NewMemory(capacity int) *Memory {
+	if capacity <= 0 {
+		capacity = DefaultMemoryCapacity
+	}
+
+	return &Memory{
+		capacity: capacity,
+		items:    make(map[string]*list.Element, capacity),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+// Set устанавливает ключ и значение в памяти
+func (m *Memory) Set(_ context.Context, key, value string, ttl time.Duration) error {
+	if key == "" {
+		return errors.New("key is nil")
+	}
+
+	if ttl <= 0 {
+		return errors.New("expiration time must be positive")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	expiresAt := m.now().Add(ttl)
+	if element, ok := m.items[key]; ok {
+		item := element.Value.(*memoryItem)
+		item.value, item.expiresAt = value, expiresAt
+		m.order.MoveToFront(element)
+		return nil
+	}
+
+	m.items[key] = m.order.PushFront(&memoryItem{key: key, value: value, expiresAt: expiresAt})
+	for m.order.Len() > m.capacity {
+		m.remove(m.order.Back())
+	}
+
+	return nil
+}
+
+// Get получает значение из памяти по ключу
+func (m *Memory) Get(_ context.Context, key string) (string, error) {
+	if key == "" {
+		return "", errors.New("key is nil")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.items[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	item := element.Value.(*memoryItem)
+	if !m.now().Before(item.expiresAt) {
+		m.remove(element)
+		return "", ErrNotFound
+	}
+
+	m.order.MoveToFront(element)
+
+	return item.value, nil
+}
+
+// Delete удаляет ключ из памяти
+func (m *Memory) Delete(_ context.Context, key string) error {
+	if key == "" {
+		return errors.New("key is nil")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.items[key]; ok {
+		m.remove(element)
+	}
+
+	return nil
+}
+
+// Keys возвращает ключи в памяти, которые начинаются с prefix и время жизни которых не истекло
+func (m *Memory) Keys(_ context.Context, prefix string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var keys []string
+	for key, element := range m.items {
+		if strings.HasPrefix(key, prefix) && m.now().Before(element.Value.(*memoryItem).expiresAt) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
+}
+
+// Len возвращает количество ключей в памяти, включая еще не удаленные просроченные
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.order.Len()
+}
+
+func (m *Memory) remove(element *list.Element) {
+	m.order.Remove(element)
+	delete(m.items, element.Value.(*memoryItem).key)
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC)
+
+	m := NewMemory(2)
+	m.now = func() time.Time { return now }
+
+	if err := m.Set(ctx, "a", "1", time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := m.Set(ctx, "b", "2", time.Hour); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	// "a" становится последним использованным, поэтому вытесняется "b"
+	if got, err := m.Get(ctx, "a"); err != nil || got != "1" {
+		t.Fatalf("Get() = %v, %v, want 1", got, err)
+	}
+	if err := m.Set(ctx, "c", "3", time.Hour); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		after   time.Duration
+		want    string
+		wantErr error
+	}{
+		{name: "evicted", key: "b", wantErr: ErrNotFound},
+		{name: "alive", key: "c", want: "3"},
+		{name: "expired", key: "a", after: time.Minute, wantErr: ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.after)
+			got, err := m.Get(ctx, tt.key)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Get() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if err := m.Delete(ctx, "c"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if m.Len() != 0 {
+		t.Errorf("Len() = %d, want 0", m.Len())
+	}
+}
+
+func TestMemory_Keys(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC)
+
+	m := NewMemory(0)
+	m.now = func() time.Time { return now }
+
+	for key, ttl := range map[string]time.Duration{"webhook:dead:1": time.Hour, "webhook:dead:2": time.Minute, "groups": time.Hour} {
+		if err := m.Set(ctx, key, "1", ttl); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+	}
+	now = now.Add(time.Minute)
+
+	keys, err := m.Keys(ctx, "webhook:dead:")
+	if err != nil || len(keys) != 1 || keys[0] != "webhook:dead:1" {
+		t.Errorf("Keys() = %v, %v, want [webhook:dead:1]", keys, err)
+	}
+}
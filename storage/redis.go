@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -12,42 +13,40 @@ type Redis struct {
 	Redis *redis.Client
 }
 
+// NewRedis создает кэш поверх клиента Redis
+func NewRedis(client *redis.Client) *Redis {
+	return &Redis{Redis: client}
+}
+
 // Set устанавливает ключ и значение в Redis
-func (c *Redis) Set(key, value string, t ...int) error {
+func (c *Redis) Set(ctx context.Context, key, value string, ttl time.Duration) error {
 	if key == "" {
 		return errors.New("key is nil")
 	}
 
-	// Устанавливаем значение по умолчанию для времени жизни ключа
-	expiration := time.Minute * 5
-	if len(t) > 0 {
-		if t[0] <= 0 {
-			return errors.New("expiration time must be positive")
-		}
-		expiration = time.Minute * time.Duration(t[0])
+	if ttl <= 0 {
+		return errors.New("expiration time must be positive")
 	}
 
-	// Тайм-аут для контекста, равный времени жизни ключа
-	ctx, cancel := context.WithTimeout(context.Background(), expiration)
-	defer cancel()
-
 	// Устанавливаем ключ в Redis
-	return c.Redis.Set(ctx, key, value, expiration).Err()
+	return c.Redis.Set(ctx, key, value, ttl).Err()
 }
 
 // Get получает значение из Redis по ключу
-func (c *Redis) Get(key string) (result string, err error) {
+func (c *Redis) Get(ctx context.Context, key string) (result string, err error) {
 	if key == "" {
 		return "", errors.New("key is nil")
 	}
 
 	// Тайм-аут для контекста
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
 	defer cancel()
 
 	// Получаем значение из Redis
 	result, err = c.Redis.Get(ctx, key).Result()
-	if err != nil {
+	if errors.Is(err, redis.Nil) {
+		return "", ErrNotFound
+	} else if err != nil {
 		return
 	}
 
@@ -58,3 +57,34 @@ func (c *Redis) Get(key string) (result string, err error) {
 
 	return
 }
+
+// Keys получает из Redis ключи, которые начинаются с prefix
+func (c *Redis) Keys(ctx context.Context, prefix string) ([]string, error) {
+	var (
+		keys   []string
+		cursor uint64
+	)
+	for {
+		batch, next, err := c.Redis.Scan(ctx, cursor, globEscaper.Replace(prefix)+"*", 100).Result()
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, batch...)
+		if cursor = next; cursor == 0 {
+			return keys, nil
+		}
+	}
+}
+
+// globEscaper экранирует специальные символы шаблона SCAN MATCH
+var globEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+
+// Delete удаляет ключ из Redis
+func (c *Redis) Delete(ctx context.Context, key string) error {
+	if key == "" {
+		return errors.New("key is nil")
+	}
+
+	return c.Redis.Del(ctx, key).Err()
+}
@@ -0,0 +1,178 @@
+package swr
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/chazari-x/hmtpk_parser/v2/coalesce"
+	"github.com/chazari-x/hmtpk_parser/v2/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// Policy время жизни данных в кэше. Первые Fresh данные считаются свежими и отдаются как есть.
+// Следующие Stale данные считаются устаревшими: они сразу отдаются с признаком stale,
+// а в фоне запрашиваются новые. Если обновить данные не удалось, устаревшие данные
+// продолжают отдаваться, пока не пройдет Fresh + Stale, а следующая попытка обновления
+// выполняется не раньше чем через минуту
+type Policy struct {
+	Fresh time.Duration
+	Stale time.Duration
+}
+
+// refreshCooldown время после неудачного фонового обновления, в течение которого ключ не обновляется повторно
+const refreshCooldown = time.Minute
+
+// entry значение в кэше вместе со временем его получения с сайта
+type entry struct {
+	Value     json.RawMessage `json:"value"`
+	FetchedAt time.Time       `json:"fetched_at"`
+}
+
+// Fetch получает данные с сайта
+type Fetch func(ctx context.Context) (any, error)
+
+// Loader получает данные из кэша или с сайта. Одновременные запросы одного ключа
+// выполняются одним запросом к сайту, каждый вызов получает свою копию данных.
+// Отмена ctx одного вызова не прерывает запрос, который ждут другие вызовы
+type Loader struct {
+	cache    storage.Cache
+	log      *logrus.Logger
+	inflight coalesce.Group
+	now      func() time.Time
+	wg       sync.WaitGroup
+
+	mu         sync.Mutex
+	refreshing map[string]bool
+	failed     map[string]time.Time
+}
+
+// NewLoader создает Loader поверх cache. Если cache равен nil, данные всегда запрашиваются с сайта
+func NewLoader(cache storage.Cache, logger *logrus.Logger) *Loader {
+	return &Loader{
+		cache:      cache,
+		log:        logger,
+		now:        time.Now,
+		refreshing: make(map[string]bool),
+		failed:     make(map[string]time.Time),
+	}
+}
+
+// Load записывает в out данные по ключу key и возвращает true, если данные устаревшие.
+// Пустые данные (null, [] или {}) в кэш не сохраняются
+func (l *Loader) Load(ctx context.Context, key string, policy Policy, fetch Fetch, out any) (stale bool, err error) {
+	if e, ok := l.get(ctx, key); ok {
+		age := l.now().Sub(e.FetchedAt)
+		if age < policy.Fresh+policy.Stale && json.Unmarshal(e.Value, out) == nil {
+			if age < policy.Fresh {
+				return false, nil
+			}
+
+			l.refresh(key, policy, fetch)
+			return true, nil
+		}
+	}
+
+	value, _, err := l.inflight.Do(ctx, key, func(ctx context.Context) (any, error) {
+		return l.fetch(ctx, key, policy, fetch)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return false, json.Unmarshal(value.(json.RawMessage), out)
+}
+
+// Wait ждет завершения фоновых обновлений
+func (l *Loader) Wait() {
+	l.wg.Wait()
+}
+
+// refresh в фоне получает новые данные, если ключ еще не обновляется
+// и с последнего неудачного обновления прошло больше refreshCooldown
+func (l *Loader) refresh(key string, policy Policy, fetch Fetch) {
+	l.mu.Lock()
+	if failed, ok := l.failed[key]; ok && l.now().Sub(failed) < refreshCooldown || l.refreshing[key] {
+		l.mu.Unlock()
+		return
+	}
+	l.refreshing[key] = true
+	l.mu.Unlock()
+
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+
+		_, _, err := l.inflight.Do(context.Background(), key, func(ctx context.Context) (any, error) {
+			return l.fetch(ctx, key, policy, fetch)
+		})
+
+		l.mu.Lock()
+		delete(l.refreshing, key)
+		if err != nil {
+			l.failed[key] = l.now()
+		} else {
+			delete(l.failed, key)
+		}
+		l.mu.Unlock()
+
+		if err != nil {
+			l.log.Warnf("refresh %s: %s", key, err)
+		}
+	}()
+}
+
+// fetch получает данные с сайта и сохраняет их в кэш
+func (l *Loader) fetch(ctx context.Context, key string, policy Policy, fetch Fetch) (json.RawMessage, error) {
+	value, err := fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.cache == nil || policy.Fresh+policy.Stale <= 0 || empty(raw) {
+		return raw, nil
+	}
+
+	marshal, err := json.Marshal(entry{Value: raw, FetchedAt: l.now()})
+	if err == nil {
+		err = l.cache.Set(ctx, key, string(marshal), policy.Fresh+policy.Stale)
+	}
+	if err != nil {
+		l.log.Error(err)
+	}
+
+	return raw, nil
+}
+
+func (l *Loader) get(ctx context.Context, key string) (entry, bool) {
+	if l.cache == nil {
+		return entry{}, false
+	}
+
+	data, err := l.cache.Get(ctx, key)
+	if err != nil || data == "" {
+		return entry{}, false
+	}
+
+	var e entry
+	if json.Unmarshal([]byte(data), &e) != nil || len(e.Value) == 0 || e.FetchedAt.IsZero() {
+		return entry{}, false
+	}
+
+	return e, true
+}
+
+func empty(raw json.RawMessage) bool {
+	switch string(raw) {
+	case "null", "[]", "{}":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,100 @@
+package swr
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chazari-x/hmtpk_parser/v2/storage"
+	"github.com/sirupsen/logrus"
+)
+
+func TestLoader_Load(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC)
+	policy := Policy{Fresh: time.Minute, Stale: time.Hour}
+	errUpstream := errors.New("upstream")
+
+	var (
+		mu      sync.Mutex
+		value   = "v1"
+		fail    bool
+		fetches int
+	)
+	fetch := func(context.Context) (any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		fetches++
+		if fail {
+			return nil, errUpstream
+		}
+		return value, nil
+	}
+
+	l := NewLoader(storage.NewMemory(0), logrus.StandardLogger())
+	l.now = func() time.Time { return now }
+
+	tests := []struct {
+		name        string
+		after       time.Duration
+		value       string
+		fail        bool
+		want        string
+		wantStale   bool
+		wantErr     error
+		wantFetches int
+	}{
+		{name: "miss", want: "v1", wantFetches: 1},
+		{name: "fresh", after: 30 * time.Second, value: "v2", want: "v1", wantFetches: 1},
+		{name: "stale revalidated in background", after: time.Minute, value: "v2", want: "v1", wantStale: true, wantFetches: 2},
+		{name: "fresh after revalidation", value: "v3", want: "v2", wantFetches: 2},
+		{name: "stale on error", after: 2 * time.Minute, fail: true, want: "v2", wantStale: true, wantFetches: 3},
+		{name: "no refresh during cooldown", fail: true, want: "v2", wantStale: true, wantFetches: 3},
+		{name: "refresh after cooldown", after: refreshCooldown, fail: true, want: "v2", wantStale: true, wantFetches: 4},
+		{name: "expired", after: 2 * time.Hour, fail: true, wantErr: errUpstream, wantFetches: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mu.Lock()
+			now = now.Add(tt.after)
+			if tt.value != "" {
+				value = tt.value
+			}
+			fail = tt.fail
+			mu.Unlock()
+
+			var got string
+			stale, err := l.Load(ctx, "2024/12:114808", policy, fetch, &got)
+			l.Wait()
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want || stale != tt.wantStale {
+				t.Errorf("Load() = %q, stale %v, want %q, stale %v", got, stale, tt.want, tt.wantStale)
+			}
+			if fetches != tt.wantFetches {
+				t.Errorf("Load() fetches = %d, want %d", fetches, tt.wantFetches)
+			}
+		})
+	}
+}
+
+func TestLoader_LoadEmpty(t *testing.T) {
+	cache := storage.NewMemory(0)
+	l := NewLoader(cache, logrus.StandardLogger())
+
+	var got []string
+	if _, err := l.Load(context.Background(), "groups", Policy{Fresh: time.Hour}, func(context.Context) (any, error) {
+		return []string{}, nil
+	}, &got); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cache.Len() != 0 {
+		t.Errorf("Load() cached empty value")
+	}
+}
@@ -1,13 +1,35 @@
 package utils
 
 import (
+	"errors"
+	"strconv"
 	"strings"
+	"time"
 
-	"github.com/chazari-x/hmtpk_parser/v2/storage"
+	"github.com/chazari-x/hmtpk_parser/v2/model"
 )
 
+// Location часовой пояс колледжа (Ханты-Мансийск, UTC+5)
+var Location = loadLocation()
+
+func loadLocation() *time.Location {
+	if location, err := time.LoadLocation("Asia/Yekaterinburg"); err == nil {
+		return location
+	}
+
+	// В системе может не быть базы часовых поясов, переход на летнее время в этом поясе отменен
+	return time.FixedZone("Asia/Yekaterinburg", 5*60*60)
+}
+
+// dateLayout формат даты, который принимает сайт hmtpk.ru
+const dateLayout = "02.01.2006"
+
 func GetDate(date string) string {
 	d := strings.Split(date, " ")
+	if len(d) < 2 || len(d[1]) < 6 {
+		return date
+	}
+
 	switch d[1][:6] {
 	case "янв":
 		d[1] = "01"
@@ -17,7 +39,7 @@ func GetDate(date string) string {
 		d[1] = "03"
 	case "апр":
 		d[1] = "04"
-	case "май":
+	case "май", "мая":
 		d[1] = "05"
 	case "июн":
 		d[1] = "06"
@@ -38,12 +60,67 @@ func GetDate(date string) string {
 	return strings.Join(d, ".")
 }
 
-func RedisIsNil(redis *storage.Redis) bool {
-	if redis != nil {
-		if redis.Redis != nil {
-			return true
+// ParseDate получает дату из заголовка дня, например "20 марта 2024, Среда" или "Среда, 20 марта 2024".
+// Дата возвращается в часовом поясе колледжа
+func ParseDate(title string) (time.Time, error) {
+	for _, part := range strings.Split(title, ",") {
+		if date, err := time.ParseInLocation(dateLayout, GetDate(strings.TrimSpace(part)), Location); err == nil {
+			return date, nil
 		}
 	}
 
-	return false
+	return time.Time{}, errors.New("date not found")
+}
+
+// FormatDate форматирует дату так, как ее принимает сайт hmtpk.ru
+func FormatDate(date time.Time) string {
+	return date.In(Location).Format(dateLayout)
+}
+
+// ParseLessonTime получает начало и конец пары из строки вида "08:30-10:00", привязывая их к дню day
+func ParseLessonTime(day time.Time, lessonTime string) (start, end time.Time, err error) {
+	bounds := strings.FieldsFunc(lessonTime, func(r rune) bool {
+		return r == '-' || r == '–' || r == '—'
+	})
+	if len(bounds) != 2 {
+		return time.Time{}, time.Time{}, errors.New("lesson time not found")
+	}
+
+	day = day.In(Location)
+	if start, err = parseClock(day, bounds[0]); err != nil {
+		return
+	}
+
+	if end, err = parseClock(day, bounds[1]); err != nil {
+		return
+	}
+
+	return
+}
+
+// WithLessonTime заполняет номер пары и время ее начала и конца в день date
+func WithLessonTime(lesson model.Lesson, date time.Time) model.Lesson {
+	lesson.Pair, _ = strconv.Atoi(strings.TrimSpace(lesson.Num))
+	if !date.IsZero() {
+		lesson.Start, lesson.End, _ = ParseLessonTime(date, lesson.Time)
+	}
+
+	return lesson
+}
+
+func parseClock(day time.Time, clock string) (time.Time, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(clock))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, Location), nil
+}
+
+// StartOfWeek возвращает начало понедельника недели, в которую входит t, в часовом поясе колледжа
+func StartOfWeek(t time.Time) time.Time {
+	t = t.In(Location)
+	offset := (int(t.Weekday()) + 6) % 7
+
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, Location)
 }
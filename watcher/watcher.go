@@ -0,0 +1,358 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/chazari-x/hmtpk_parser/v2/diff"
+	hmtpkErrors "github.com/chazari-x/hmtpk_parser/v2/errors"
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+	"github.com/chazari-x/hmtpk_parser/v2/schedule"
+	"github.com/chazari-x/hmtpk_parser/v2/storage"
+	"github.com/chazari-x/hmtpk_parser/v2/utils"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultInterval = time.Minute * 10
+	DefaultJitter   = time.Minute
+
+	// snapshotTTL время жизни последнего полученного расписания в кэше
+	snapshotTTL = time.Hour * 24 * 14
+
+	announcesKey = "watch:announces"
+)
+
+// Source получает расписание на неделю, в которую входит date. Реализуется *hmtpk_parser.Controller
+type Source interface {
+	GetSchedule(ctx context.Context, kind schedule.Kind, subject string, date time.Time) ([]model.Schedule, error)
+}
+
+// AnnounceSource получает страницу объявлений. Реализуется *hmtpk_parser.Controller
+type AnnounceSource interface {
+	GetAnnounces(ctx context.Context, page int) (model.Announces, error)
+}
+
+// EventType вид события
+type EventType string
+
+const (
+	// ScheduleChanged изменилось расписание группы или преподавателя
+	ScheduleChanged EventType = "schedule"
+	// AnnouncesAdded на первой странице появились новые объявления
+	AnnouncesAdded EventType = "announce"
+)
+
+// Event изменение расписания группы или преподавателя на одной неделе или новые объявления
+type Event struct {
+	Type    EventType     `json:"type"`
+	Kind    schedule.Kind `json:"kind,omitempty"`
+	Subject string        `json:"subject,omitempty"`
+	// Week понедельник недели, на которой изменилось расписание, для событий ScheduleChanged
+	Week      *time.Time       `json:"week,omitempty"`
+	Changes   []diff.Day       `json:"changes,omitempty"`
+	Announces []model.Announce `json:"announces,omitempty"`
+}
+
+// Subscription отслеживаемое расписание
+type Subscription struct {
+	Kind    schedule.Kind
+	Subject string
+	// Weeks количество отслеживаемых недель, начиная с текущей
+	Weeks int
+}
+
+// Option настраивает Watcher при создании
+type Option func(*Watcher)
+
+// WithInterval задает период опроса сайта. Значение меньше или равное нулю заменяется на DefaultInterval
+func WithInterval(interval time.Duration) Option {
+	return func(w *Watcher) {
+		w.interval = interval
+	}
+}
+
+// WithJitter задает наибольшую случайную добавку к периоду опроса,
+// чтобы несколько экземпляров не обращались к сайту одновременно
+func WithJitter(jitter time.Duration) Option {
+	return func(w *Watcher) {
+		w.jitter = jitter
+	}
+}
+
+// Watcher периодически получает расписания из подписок, сравнивает их с предыдущими,
+// сохраненными в кэше, и сообщает об изменениях обработчикам и в канал Events
+type Watcher struct {
+	source   Source
+	cache    storage.Cache
+	log      *logrus.Logger
+	interval time.Duration
+	jitter   time.Duration
+	now      func() time.Time
+
+	mu            sync.Mutex
+	subscriptions map[string]Subscription
+	announces     AnnounceSource
+	handlers      []func(Event)
+	events        chan Event
+	started       bool
+}
+
+// New создает Watcher. Последние полученные расписания хранятся в cache,
+// если cache равен nil, они хранятся в памяти процесса
+func New(source Source, cache storage.Cache, logger *logrus.Logger, opts ...Option) *Watcher {
+	if cache == nil {
+		cache = storage.NewMemory(0)
+	}
+
+	w := &Watcher{
+		source:        source,
+		cache:         cache,
+		log:           logger,
+		interval:      DefaultInterval,
+		jitter:        DefaultJitter,
+		now:           time.Now,
+		subscriptions: make(map[string]Subscription),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.interval <= 0 {
+		w.interval = DefaultInterval
+	}
+
+	return w
+}
+
+// Watch добавляет подписку на изменения расписания на weeks недель, начиная с текущей
+func (w *Watcher) Watch(kind schedule.Kind, subject string, weeks int) {
+	if weeks < 1 {
+		weeks = 1
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.subscriptions[subscriptionKey(kind, subject)] = Subscription{Kind: kind, Subject: subject, Weeks: weeks}
+}
+
+// Unwatch удаляет подписку
+func (w *Watcher) Unwatch(kind schedule.Kind, subject string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delete(w.subscriptions, subscriptionKey(kind, subject))
+}
+
+// WatchAnnounces добавляет подписку на новые объявления на первой странице
+func (w *Watcher) WatchAnnounces(source AnnounceSource) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.announces = source
+}
+
+// Subscriptions возвращает текущие подписки
+func (w *Watcher) Subscriptions() []Subscription {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	subscriptions := make([]Subscription, 0, len(w.subscriptions))
+	for _, subscription := range w.subscriptions {
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions
+}
+
+// OnChange добавляет обработчик изменений. Обработчики вызываются последовательно в горутине Run
+func (w *Watcher) OnChange(handler func(Event)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.handlers = append(w.handlers, handler)
+}
+
+// Events возвращает канал с изменениями. Канал создается при первом вызове и закрывается
+// по завершении Run. Пока канал не прочитан, опрос приостанавливается
+func (w *Watcher) Events() <-chan Event {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.events == nil {
+		w.events = make(chan Event)
+	}
+
+	return w.events
+}
+
+// Run опрашивает сайт до отмены ctx. Run можно вызвать только один раз,
+// повторный вызов возвращает errors.ErrorStarted
+func (w *Watcher) Run(ctx context.Context) error {
+	w.mu.Lock()
+	if w.started {
+		w.mu.Unlock()
+		return hmtpkErrors.ErrorStarted
+	}
+	w.started = true
+	w.mu.Unlock()
+
+	defer func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		if w.events != nil {
+			close(w.events)
+		}
+	}()
+
+	for {
+		w.Poll(ctx)
+
+		timer := time.NewTimer(w.delay())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Poll однократно опрашивает все подписки и сообщает о найденных изменениях
+func (w *Watcher) Poll(ctx context.Context) {
+	monday := utils.StartOfWeek(w.now())
+	for _, subscription := range w.Subscriptions() {
+		for week := 0; week < subscription.Weeks; week++ {
+			if ctx.Err() != nil {
+				return
+			}
+
+			event, err := w.check(ctx, subscription, monday.AddDate(0, 0, 7*week))
+			if err != nil {
+				w.log.Error(err)
+				continue
+			}
+
+			if len(event.Changes) != 0 {
+				w.emit(ctx, event)
+			}
+		}
+	}
+
+	w.mu.Lock()
+	announces := w.announces
+	w.mu.Unlock()
+
+	if announces != nil && ctx.Err() == nil {
+		event, err := w.checkAnnounces(ctx, announces)
+		if err != nil {
+			w.log.Error(err)
+		} else if len(event.Announces) != 0 {
+			w.emit(ctx, event)
+		}
+	}
+}
+
+// check получает расписание на неделю и сравнивает его с сохраненным
+func (w *Watcher) check(ctx context.Context, subscription Subscription, monday time.Time) (Event, error) {
+	event := Event{Type: ScheduleChanged, Kind: subscription.Kind, Subject: subscription.Subject, Week: &monday}
+
+	current, err := w.source.GetSchedule(ctx, subscription.Kind, subscription.Subject, monday)
+	if err != nil {
+		return event, fmt.Errorf("watch %s %s: %w", subscription.Kind, subscription.Subject, err)
+	}
+
+	key := snapshotKey(subscription, monday)
+	if data, err := w.cache.Get(ctx, key); err == nil {
+		var previous []model.Schedule
+		if json.Unmarshal([]byte(data), &previous) == nil {
+			event.Changes = diff.Compare(previous, current)
+		}
+	}
+
+	if marshal, err := json.Marshal(current); err == nil {
+		if err = w.cache.Set(ctx, key, string(marshal), snapshotTTL); err != nil {
+			w.log.Error(err)
+		}
+	}
+
+	return event, nil
+}
+
+// checkAnnounces получает первую страницу объявлений и ищет объявления, которых не было при прошлом опросе
+func (w *Watcher) checkAnnounces(ctx context.Context, source AnnounceSource) (Event, error) {
+	event := Event{Type: AnnouncesAdded}
+
+	current, err := source.GetAnnounces(ctx, 1)
+	if err != nil {
+		return event, fmt.Errorf("watch announces: %w", err)
+	}
+
+	if data, err := w.cache.Get(ctx, announcesKey); err == nil {
+		var known []string
+		if json.Unmarshal([]byte(data), &known) == nil {
+			seen := make(map[string]bool, len(known))
+			for _, path := range known {
+				seen[path] = true
+			}
+
+			for _, announce := range current.Announces {
+				if !seen[announce.Path] {
+					event.Announces = append(event.Announces, announce)
+				}
+			}
+		}
+	}
+
+	paths := make([]string, 0, len(current.Announces))
+	for _, announce := range current.Announces {
+		paths = append(paths, announce.Path)
+	}
+
+	if marshal, err := json.Marshal(paths); err == nil {
+		if err = w.cache.Set(ctx, announcesKey, string(marshal), snapshotTTL); err != nil {
+			w.log.Error(err)
+		}
+	}
+
+	return event, nil
+}
+
+func (w *Watcher) emit(ctx context.Context, event Event) {
+	w.mu.Lock()
+	handlers, events := w.handlers, w.events
+	w.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+
+	if events != nil {
+		select {
+		case events <- event:
+		case <-ctx.Done():
+		}
+	}
+}
+
+func (w *Watcher) delay() time.Duration {
+	if w.jitter <= 0 {
+		return w.interval
+	}
+
+	return w.interval + time.Duration(rand.Int63n(int64(w.jitter)))
+}
+
+func subscriptionKey(kind schedule.Kind, subject string) string {
+	return string(kind) + ":" + subject
+}
+
+func snapshotKey(subscription Subscription, monday time.Time) string {
+	year, week := monday.ISOWeek()
+	return fmt.Sprintf("watch:%s:%d/%d:%s", subscription.Kind, year, week, subscription.Subject)
+}
@@ -0,0 +1,172 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chazari-x/hmtpk_parser/v2/diff"
+	hmtpkErrors "github.com/chazari-x/hmtpk_parser/v2/errors"
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+	"github.com/chazari-x/hmtpk_parser/v2/schedule"
+	"github.com/chazari-x/hmtpk_parser/v2/utils"
+	"github.com/sirupsen/logrus"
+)
+
+type fakeSource struct {
+	mu    sync.Mutex
+	room  string
+	dates []time.Time
+}
+
+func (s *fakeSource) GetSchedule(_ context.Context, _ schedule.Kind, _ string, date time.Time) ([]model.Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.dates = append(s.dates, date)
+
+	return []model.Schedule{{
+		Date:    date,
+		Lessons: []model.Lesson{{Num: "1", Pair: 1, Name: "Математика", Room: s.room}},
+	}}, nil
+}
+
+func (s *fakeSource) polls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.dates)
+}
+
+func (s *fakeSource) setRoom(room string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.room = room
+}
+
+func TestWatcher_Poll(t *testing.T) {
+	ctx := context.Background()
+	source := &fakeSource{room: "205"}
+
+	w := New(source, nil, logrus.StandardLogger())
+	w.now = func() time.Time { return time.Date(2024, 3, 20, 12, 0, 0, 0, utils.Location) }
+	w.Watch(schedule.Group, "114808", 2)
+
+	var events []Event
+	w.OnChange(func(event Event) {
+		events = append(events, event)
+	})
+
+	w.Poll(ctx)
+	if len(events) != 0 {
+		t.Fatalf("Poll() without snapshot emitted %v", events)
+	}
+
+	wantWeeks := []time.Time{
+		time.Date(2024, 3, 18, 0, 0, 0, 0, utils.Location),
+		time.Date(2024, 3, 25, 0, 0, 0, 0, utils.Location),
+	}
+	for i, date := range source.dates {
+		if !date.Equal(wantWeeks[i]) {
+			t.Errorf("Poll() requested week %v, want %v", date, wantWeeks[i])
+		}
+	}
+
+	w.Poll(ctx)
+	if len(events) != 0 {
+		t.Fatalf("Poll() without changes emitted %v", events)
+	}
+
+	source.setRoom("312")
+	w.Poll(ctx)
+	if len(events) != 2 {
+		t.Fatalf("Poll() emitted %d events, want 2", len(events))
+	}
+
+	change := events[0].Changes[0].Changes[0]
+	if events[0].Subject != "114808" || change.Type != diff.Modified || change.New.Room != "312" {
+		t.Errorf("Poll() event = %+v", events[0])
+	}
+}
+
+func TestWatcher_Run(t *testing.T) {
+	source := &fakeSource{room: "205"}
+
+	w := New(source, nil, logrus.StandardLogger(), WithInterval(time.Millisecond), WithJitter(0))
+	w.Watch(schedule.Teacher, "Иванов Иван Иванович", 1)
+	events := w.Events()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error)
+	go func() {
+		done <- w.Run(ctx)
+	}()
+
+	// Меняем расписание только после первого опроса, сохранившего исходное расписание
+	for source.polls() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	source.setRoom("312")
+
+	select {
+	case event := <-events:
+		if event.Kind != schedule.Teacher {
+			t.Errorf("Run() event kind = %v, want %v", event.Kind, schedule.Teacher)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("Run() did not emit an event")
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Run() error = %v, want %v", err, context.Canceled)
+	}
+	if _, ok := <-events; ok {
+		t.Errorf("Run() must close the events channel")
+	}
+	if err := w.Run(context.Background()); !errors.Is(err, hmtpkErrors.ErrorStarted) {
+		t.Errorf("second Run() error = %v, want %v", err, hmtpkErrors.ErrorStarted)
+	}
+}
+
+func TestNew_Interval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if w := New(nil, nil, logrus.StandardLogger(), WithInterval(interval), WithJitter(0)); w.delay() != DefaultInterval {
+			t.Errorf("New(WithInterval(%v)) delay = %v, want %v", interval, w.delay(), DefaultInterval)
+		}
+	}
+}
+
+type fakeAnnounces struct {
+	announces []model.Announce
+}
+
+func (s *fakeAnnounces) GetAnnounces(_ context.Context, _ int) (model.Announces, error) {
+	return model.Announces{Announces: s.announces, LastPage: 1}, nil
+}
+
+func TestWatcher_WatchAnnounces(t *testing.T) {
+	ctx := context.Background()
+	source := &fakeAnnounces{announces: []model.Announce{{Path: "/1/", Title: "Первое"}}}
+
+	w := New(nil, nil, logrus.StandardLogger())
+	w.WatchAnnounces(source)
+
+	var events []Event
+	w.OnChange(func(event Event) {
+		events = append(events, event)
+	})
+
+	w.Poll(ctx)
+	source.announces = append([]model.Announce{{Path: "/2/", Title: "Второе"}}, source.announces...)
+	w.Poll(ctx)
+
+	if len(events) != 1 || events[0].Type != AnnouncesAdded || len(events[0].Announces) != 1 || events[0].Announces[0].Path != "/2/" {
+		t.Errorf("Poll() events = %+v, want one new announce /2/", events)
+	}
+}
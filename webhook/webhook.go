@@ -0,0 +1,391 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/chazari-x/hmtpk_parser/v2/backoff"
+	"github.com/chazari-x/hmtpk_parser/v2/storage"
+	"github.com/chazari-x/hmtpk_parser/v2/watcher"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultAttempts   = 5
+	DefaultBackoff    = time.Second
+	DefaultMaxBackoff = time.Minute
+
+	// SignatureHeader заголовок с подписью тела запроса: "sha256=" и HMAC-SHA256 тела в hex
+	SignatureHeader = "X-Hmtpk-Signature"
+	// EventHeader заголовок с видом события
+	EventHeader = "X-Hmtpk-Event"
+	// DeliveryHeader заголовок с идентификатором доставки, одинаковым для всех попыток
+	DeliveryHeader = "X-Hmtpk-Delivery"
+
+	deadLettersPrefix = "webhook:dead:"
+	deadLetterTTL     = time.Hour * 24 * 7
+)
+
+// Payload тело запроса, которое получает адрес подписки
+type Payload struct {
+	ID        string            `json:"id"`
+	Type      watcher.EventType `json:"type"`
+	CreatedAt time.Time         `json:"created_at"`
+	Data      watcher.Event     `json:"data"`
+}
+
+// DeadLetter доставка, которая не удалась после всех попыток
+type DeadLetter struct {
+	URL      string    `json:"url"`
+	Payload  Payload   `json:"payload"`
+	Error    string    `json:"error"`
+	Attempts int       `json:"attempts"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+type endpoint struct {
+	url    string
+	secret string
+}
+
+// Option настраивает Dispatcher при создании
+type Option func(*Dispatcher)
+
+// WithAttempts задает количество попыток доставки
+func WithAttempts(attempts int) Option {
+	return func(d *Dispatcher) {
+		d.attempts = attempts
+	}
+}
+
+// WithBackoff задает задержку перед второй попыткой и наибольшую задержку между попытками.
+// Задержка удваивается после каждой неудачной попытки
+func WithBackoff(backoff, maxBackoff time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.backoff, d.maxBackoff = backoff, maxBackoff
+	}
+}
+
+// Dispatcher отправляет события watcher.Watcher на зарегистрированные адреса
+// POST запросом с подписанным JSON. Неудавшиеся доставки сохраняются в кэш,
+// каждая под своим ключом
+type Dispatcher struct {
+	client     *http.Client
+	cache      storage.Cache
+	log        *logrus.Logger
+	attempts   int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	now        func() time.Time
+	ctx        context.Context
+	cancel     context.CancelFunc
+
+	mu        sync.Mutex
+	endpoints []endpoint
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// New создает Dispatcher. Если client равен nil, используется http.DefaultClient,
+// если cache равен nil, неудавшиеся доставки хранятся в памяти процесса.
+// Чтобы DeadLetters возвращал доставки, cache должен реализовывать storage.Lister
+func New(client *http.Client, cache storage.Cache, logger *logrus.Logger, opts ...Option) *Dispatcher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	if cache == nil {
+		cache = storage.NewMemory(0)
+	}
+
+	d := &Dispatcher{
+		client:     client,
+		cache:      cache,
+		log:        logger,
+		attempts:   DefaultAttempts,
+		backoff:    DefaultBackoff,
+		maxBackoff: DefaultMaxBackoff,
+		now:        time.Now,
+	}
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// Register добавляет адрес, на который отправляются события. Тело запроса подписывается
+// ключом secret, подпись передается в заголовке SignatureHeader
+func (d *Dispatcher) Register(url, secret string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i := range d.endpoints {
+		if d.endpoints[i].url == url {
+			d.endpoints[i].secret = secret
+			return
+		}
+	}
+
+	d.endpoints = append(d.endpoints, endpoint{url: url, secret: secret})
+}
+
+// Unregister удаляет адрес
+func (d *Dispatcher) Unregister(url string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i := range d.endpoints {
+		if d.endpoints[i].url == url {
+			d.endpoints = append(d.endpoints[:i], d.endpoints[i+1:]...)
+			return
+		}
+	}
+}
+
+// Handle отправляет событие в фоне, не задерживая опрос сайта. Подходит для watcher.Watcher.OnChange.
+// Доставки прерываются Close, события после Close отбрасываются
+func (d *Dispatcher) Handle(event watcher.Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+
+		if err := d.Dispatch(d.ctx, event); err != nil {
+			d.log.Error(err)
+		}
+	}()
+}
+
+// Wait ожидает завершения доставок, начатых Handle
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// Close прерывает повторы доставок, начатых Handle, и ждет их завершения или отмены ctx.
+// Прерванные доставки сохраняются как DeadLetter. События, переданные в Handle после Close, отбрасываются
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	d.closed = true
+	d.mu.Unlock()
+
+	d.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Dispatch отправляет событие на все адреса и ожидает завершения всех попыток.
+// Возвращает ошибки доставок, которые не удались и были сохранены как DeadLetter
+func (d *Dispatcher) Dispatch(ctx context.Context, event watcher.Event) error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+
+	payload := Payload{ID: id, Type: event.Type, CreatedAt: d.now().UTC(), Data: event}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	endpoints := append([]endpoint(nil), d.endpoints...)
+	d.mu.Unlock()
+
+	errs := make([]error, len(endpoints))
+	var wg sync.WaitGroup
+	for i := range endpoints {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			attempts, err := d.deliver(ctx, endpoints[i], payload, body)
+			if err == nil {
+				return
+			}
+
+			errs[i] = fmt.Errorf("webhook %s: %w", endpoints[i].url, err)
+			d.saveDeadLetter(DeadLetter{
+				URL:      endpoints[i].url,
+				Payload:  payload,
+				Error:    err.Error(),
+				Attempts: attempts,
+				FailedAt: d.now().UTC(),
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// DeadLetters возвращает неудавшиеся доставки, время хранения которых еще не истекло, по порядку времени
+func (d *Dispatcher) DeadLetters(ctx context.Context) ([]DeadLetter, error) {
+	lister, ok := d.cache.(storage.Lister)
+	if !ok {
+		return nil, errors.New("webhook: cache does not implement storage.Lister")
+	}
+
+	keys, err := lister.Keys(ctx, deadLettersPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	letters := make([]DeadLetter, 0, len(keys))
+	for _, key := range keys {
+		data, err := d.cache.Get(ctx, key)
+		if errors.Is(err, storage.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		var letter DeadLetter
+		if err = json.Unmarshal([]byte(data), &letter); err != nil {
+			return nil, err
+		}
+		letters = append(letters, letter)
+	}
+
+	sort.Slice(letters, func(i, j int) bool {
+		return letters[i].FailedAt.Before(letters[j].FailedAt)
+	})
+
+	return letters, nil
+}
+
+// permanentError ошибка, после которой повторять запрос бессмысленно
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+// deliver отправляет тело на адрес с повторами и возвращает количество сделанных попыток
+func (d *Dispatcher) deliver(ctx context.Context, e endpoint, payload Payload, body []byte) (int, error) {
+	var err error
+	for attempt := 1; attempt <= d.attempts; attempt++ {
+		if err = d.post(ctx, e, payload, body); err == nil {
+			return attempt, nil
+		}
+
+		var permanent permanentError
+		if errors.As(err, &permanent) || attempt == d.attempts {
+			return attempt, err
+		}
+
+		if err := backoff.Sleep(ctx, backoff.Delay(d.backoff, d.maxBackoff, attempt)); err != nil {
+			return attempt, err
+		}
+	}
+
+	return d.attempts, err
+}
+
+func (d *Dispatcher) post(ctx context.Context, e endpoint, payload Payload, body []byte) error {
+	request, err := http.NewRequestWithContext(ctx, "POST", e.url, bytes.NewReader(body))
+	if err != nil {
+		return permanentError{err}
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EventHeader, string(payload.Type))
+	request.Header.Set(DeliveryHeader, payload.ID)
+	request.Header.Set(SignatureHeader, Sign(e.secret, body))
+
+	resp, err := d.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("bad response: %s", resp.Status)
+	default:
+		return permanentError{fmt.Errorf("bad response: %s", resp.Status)}
+	}
+}
+
+// saveDeadLetter сохраняет неудавшуюся доставку под отдельным ключом, чтобы несколько экземпляров
+// с общим кэшем не перезаписывали доставки друг друга
+func (d *Dispatcher) saveDeadLetter(letter DeadLetter) {
+	// Тайм-аут, чтобы недоступный кэш не задерживал доставку остальных событий
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	id, err := newID()
+	if err != nil {
+		d.log.Error(err)
+		return
+	}
+
+	marshal, err := json.Marshal(letter)
+	if err != nil {
+		d.log.Error(err)
+		return
+	}
+
+	if err = d.cache.Set(ctx, deadLettersPrefix+id, string(marshal), deadLetterTTL); err != nil {
+		d.log.Error(err)
+	}
+}
+
+// Sign возвращает подпись тела запроса ключом secret в формате заголовка SignatureHeader
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify проверяет подпись тела запроса, полученную в заголовке SignatureHeader
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+func newID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,134 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chazari-x/hmtpk_parser/v2/model"
+	"github.com/chazari-x/hmtpk_parser/v2/storage"
+	"github.com/chazari-x/hmtpk_parser/v2/watcher"
+	"github.com/sirupsen/logrus"
+)
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	const secret = "secret"
+
+	event := watcher.Event{
+		Type:      watcher.AnnouncesAdded,
+		Announces: []model.Announce{{Path: "/ru/press-center/announce/1/", Title: "Изменение расписания"}},
+	}
+
+	var flakyCalls, brokenCalls, rejectedCalls atomic.Int32
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !Verify(secret, body, r.Header.Get(SignatureHeader)) {
+			t.Errorf("Dispatch() sent invalid signature %q", r.Header.Get(SignatureHeader))
+		}
+
+		var payload Payload
+		if err := json.Unmarshal(body, &payload); err != nil || payload.Data.Announces[0].Title != "Изменение расписания" || bytes.Contains(body, []byte(`"week"`)) {
+			t.Errorf("Dispatch() sent payload %s, error = %v", body, err)
+		}
+
+		if flakyCalls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer flaky.Close()
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		brokenCalls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer broken.Close()
+
+	rejected := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rejectedCalls.Add(1)
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer rejected.Close()
+
+	d := New(nil, nil, logrus.StandardLogger(), WithAttempts(4), WithBackoff(time.Millisecond, time.Millisecond*5))
+	d.Register(flaky.URL, secret)
+	d.Register(broken.URL, secret)
+	d.Register(rejected.URL, secret)
+
+	if err := d.Dispatch(context.Background(), event); err == nil {
+		t.Errorf("Dispatch() error = nil, want errors for failed endpoints")
+	}
+
+	tests := []struct {
+		name  string
+		calls *atomic.Int32
+		want  int32
+	}{
+		{name: "retried until success", calls: &flakyCalls, want: 3},
+		{name: "retried until attempts end", calls: &brokenCalls, want: 4},
+		{name: "client error is not retried", calls: &rejectedCalls, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.calls.Load(); got != tt.want {
+				t.Errorf("Dispatch() calls = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	letters, err := d.DeadLetters(context.Background())
+	if err != nil {
+		t.Fatalf("DeadLetters() error = %v", err)
+	}
+	if len(letters) != 2 {
+		t.Fatalf("DeadLetters() got = %+v, want 2 letters", letters)
+	}
+	for _, letter := range letters {
+		if letter.URL == flaky.URL || letter.Payload.Type != watcher.AnnouncesAdded {
+			t.Errorf("DeadLetters() unexpected letter %+v", letter)
+		}
+	}
+}
+
+func TestDispatcher_Close(t *testing.T) {
+	started := make(chan struct{}, 2)
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	// два экземпляра с общим кэшем не перезаписывают неудавшиеся доставки друг друга
+	cache := storage.NewMemory(0)
+	dispatchers := []*Dispatcher{
+		New(nil, cache, logrus.StandardLogger(), WithBackoff(time.Hour, time.Hour)),
+		New(nil, cache, logrus.StandardLogger(), WithBackoff(time.Hour, time.Hour)),
+	}
+	for _, d := range dispatchers {
+		d.Register(down.URL, "secret")
+		d.Handle(watcher.Event{Type: watcher.AnnouncesAdded})
+		<-started
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	for _, d := range dispatchers {
+		if err := d.Close(ctx); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	}
+
+	// событие после Close отбрасывается и не попадает в неудавшиеся доставки
+	dispatchers[0].Handle(watcher.Event{Type: watcher.AnnouncesAdded})
+	dispatchers[0].Wait()
+
+	letters, err := dispatchers[0].DeadLetters(context.Background())
+	if err != nil || len(letters) != 2 {
+		t.Errorf("DeadLetters() = %+v, %v, want 2 letters", letters, err)
+	}
+}